@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxPlaceholders is the max number of placeholders of a MySQL prepared statement
+const MaxPlaceholders = 65535
+
+var (
+	errBatchRowTooLarge = errors.New("[builder] a single row exceeds the limit of BatchLimit")
+	errBatchNegative    = errors.New("[builder] BatchLimit can't be negative")
+)
+
+// BatchLimit limits each statement built by BuildInsertBatches, zero means no limit
+type BatchLimit struct {
+	// Rows is the max number of rows
+	Rows int
+	// Placeholders is the max number of placeholders, MySQL allows at most MaxPlaceholders
+	Placeholders int
+	// Bytes is the max estimated size of the statement and its arguments,
+	// keep it below max_allowed_packet of MySQL
+	Bytes int
+}
+
+// Statement is a built statement with its arguments
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// BuildInsertBatches is the same as BuildInsert, but splits data
+// into as few statements as possible within limit
+func BuildInsertBatches(table string, data []map[string]interface{}, limit BatchLimit) ([]Statement, error) {
+	return defaultBuilder.BuildInsertBatches(table, data, limit)
+}
+
+// BuildInsertBatches is the same as the package-level BuildInsertBatches but in the dialect of b
+func (b *Builder) BuildInsertBatches(table string, data []map[string]interface{}, limit BatchLimit) ([]Statement, error) {
+	if limit.Rows < 0 || limit.Placeholders < 0 || limit.Bytes < 0 {
+		return nil, errBatchNegative
+	}
+	verb, suffix, err := b.insertVerb(commonInsert)
+	if nil != err {
+		return nil, err
+	}
+	rows, err := b.resolveInsert(table, data)
+	if nil != err {
+		return nil, err
+	}
+	head := fmt.Sprintf("%s %s (%s) VALUES ", verb, rows.table, strings.Join(rows.fields, ","))
+	var stmts []Statement
+	var sets []string
+	var vals []interface{}
+	size := 0
+	flush := func() {
+		cond := head + strings.Join(sets, ",") + suffix
+		stmts = append(stmts, Statement{SQL: rebind(b.dialect, cond), Args: vals})
+		sets, vals, size = nil, nil, 0
+	}
+	for i, row := range rows.rows {
+		rowVals := rows.vals[i]
+		rowSize := len(row) + 1 + estimateSize(rowVals)
+		if exceed(limit.Placeholders, 0, len(rowVals)) ||
+			exceed(limit.Bytes, len(head)+len(suffix), rowSize+b.placeholderSize(0, len(rowVals))) {
+			return nil, errBatchRowTooLarge
+		}
+		if len(sets) > 0 && (exceed(limit.Rows, len(sets), 1) ||
+			exceed(limit.Placeholders, len(vals), len(rowVals)) ||
+			exceed(limit.Bytes, len(head)+len(suffix)+size, rowSize+b.placeholderSize(len(vals), len(rowVals)))) {
+			flush()
+		}
+		size += rowSize + b.placeholderSize(len(vals), len(rowVals))
+		sets = append(sets, row)
+		vals = append(vals, rowVals...)
+	}
+	flush()
+	return stmts, nil
+}
+
+// placeholderSize returns the bytes the placeholders of the args used+1 to used+n take
+// more than ?, such as $10 of PostgreSQL takes 2 more
+func (b *Builder) placeholderSize(used, n int) int {
+	size := 0
+	for i := used + 1; i <= used+n; i++ {
+		size += len(b.dialect.Placeholder(i)) - len(paramPlaceHolder)
+	}
+	return size
+}
+
+// exceed reports whether adding n to used exceeds limit, zero limit means no limit
+func exceed(limit, used, n int) bool {
+	return limit > 0 && used+n > limit
+}
+
+// estimateSize estimates the bytes of vals sent to the database
+func estimateSize(vals []interface{}) int {
+	size := 0
+	for _, val := range vals {
+		switch v := val.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case time.Time:
+			size += len("2006-01-02 15:04:05.999999")
+		case nil:
+			size++
+		default:
+			size += 8
+		}
+	}
+	return size
+}
@@ -0,0 +1,89 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchData(n int) []map[string]interface{} {
+	data := make([]map[string]interface{}, n)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": i, "name": "deen"}
+	}
+	return data
+}
+
+func TestBuildInsertBatches(t *testing.T) {
+	var data = []struct {
+		limit BatchLimit
+		conds []string
+	}{
+		{
+			limit: BatchLimit{},
+			conds: []string{"INSERT INTO tb (id,name) VALUES (?,?),(?,?),(?,?),(?,?),(?,?)"},
+		},
+		{
+			limit: BatchLimit{Rows: 2},
+			conds: []string{
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?)",
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?)",
+				"INSERT INTO tb (id,name) VALUES (?,?)",
+			},
+		},
+		{
+			limit: BatchLimit{Rows: 4, Placeholders: 7},
+			conds: []string{
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?),(?,?)",
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?)",
+			},
+		},
+		{
+			// the head is 32 bytes and each row is 18 bytes
+			limit: BatchLimit{Bytes: 32 + 18*2},
+			conds: []string{
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?)",
+				"INSERT INTO tb (id,name) VALUES (?,?),(?,?)",
+				"INSERT INTO tb (id,name) VALUES (?,?)",
+			},
+		},
+	}
+	ass := assert.New(t)
+	rows := batchData(5)
+	for _, tc := range data {
+		stmts, err := BuildInsertBatches("tb", rows, tc.limit)
+		ass.NoError(err)
+		var conds []string
+		var vals []interface{}
+		for _, stmt := range stmts {
+			conds = append(conds, stmt.SQL)
+			vals = append(vals, stmt.Args...)
+		}
+		ass.Equal(tc.conds, conds, "limit:%+v", tc.limit)
+		ass.Equal([]interface{}{0, "deen", 1, "deen", 2, "deen", 3, "deen", 4, "deen"}, vals)
+	}
+
+	stmts, err := New(PostgreSQL).BuildInsertBatches("tb", rows[:3], BatchLimit{Rows: 2})
+	ass.NoError(err)
+	ass.Equal([]Statement{
+		{SQL: "INSERT INTO tb (id,name) VALUES ($1,$2),($3,$4)", Args: []interface{}{0, "deen", 1, "deen"}},
+		{SQL: "INSERT INTO tb (id,name) VALUES ($1,$2)", Args: []interface{}{2, "deen"}},
+	}, stmts)
+
+	// each row is 18 bytes before rebinding, ($1,$2) to ($7,$8) are 20 bytes after, ($9,$10) is 21 and ($11,$12) is 22
+	stmts, err = New(PostgreSQL).BuildInsertBatches("tb", batchData(6), BatchLimit{Bytes: 32 + 18*6})
+	ass.NoError(err)
+	ass.Equal([]string{
+		"INSERT INTO tb (id,name) VALUES ($1,$2),($3,$4),($5,$6),($7,$8),($9,$10)",
+		"INSERT INTO tb (id,name) VALUES ($1,$2)",
+	}, []string{stmts[0].SQL, stmts[1].SQL}, "the placeholders are measured after rebinding")
+
+	_, err = BuildInsertBatches("tb", rows, BatchLimit{Placeholders: 1})
+	ass.Equal(errBatchRowTooLarge, err)
+	_, err = BuildInsertBatches("tb", rows, BatchLimit{Bytes: 40})
+	ass.Equal(errBatchRowTooLarge, err)
+	_, err = BuildInsertBatches("tb", rows, BatchLimit{Rows: -1})
+	ass.Equal(errBatchNegative, err)
+	_, err = BuildInsertBatches("tb", nil, BatchLimit{})
+	ass.Equal(errInsertNullData, err)
+}
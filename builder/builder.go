@@ -13,6 +13,7 @@ var (
 	// ErrUnsupportedOperator reports there's unsupported operators in where-condition
 	ErrUnsupportedOperator       = errors.New("[builder] unsupported operator")
 	errOrValueType               = errors.New(`[builder] the value of "_or" must be of slice of map[string]interface{} type`)
+	errAndValueType              = errors.New(`[builder] the value of "_and" must be of slice of map[string]interface{} type`)
 	errOrderByValueType          = errors.New(`[builder] the value of "_orderby" must be of string type`)
 	errGroupByValueType          = errors.New(`[builder] the value of "_groupby" must be of string type`)
 	errLimitValueType            = errors.New(`[builder] the value of "_limit" must be of []uint type`)
@@ -22,6 +23,9 @@ var (
 	errLockModeValueType         = errors.New(`[builder] the value of "_lockMode" must be of string type`)
 	errNotAllowedLockMode        = errors.New(`[builder] the value of "_lockMode" is not allowed`)
 	errUpdateLimitType           = errors.New(`[builder] the value of "_limit" in update query must be one of int,uint,int64,uint64`)
+	errJoinValueType             = errors.New(`[builder] the value of "_join" must be of []Join type`)
+	errExistsValueType           = errors.New(`[builder] the value of "_exists" and "_not_exists" must be of *Subquery or []*Subquery type`)
+	errNilSubquery               = errors.New(`[builder] subquery can't be nil`)
 
 	errWhereInterfaceSliceType = `[builder] the value of "xxx %s" must be of []interface{} type`
 	errEmptySliceCondition     = `[builder] the value of "%s" must contain at least one element`
@@ -32,6 +36,9 @@ var (
 		"_having":   struct{}{},
 		"_limit":    struct{}{},
 		"_lockMode": struct{}{},
+		"_join":     struct{}{},
+		"_with":     struct{}{},
+		"_keyset":   struct{}{},
 	}
 )
 
@@ -55,6 +62,56 @@ type eleLimit struct {
 	begin, step uint
 }
 
+// Builder builds statements for a specific Dialect.
+// The package-level Build* functions use a MySQL Builder.
+type Builder struct {
+	dialect Dialect
+	quote   QuoteMode
+}
+
+var defaultBuilder = New(MySQL)
+
+// New returns a Builder which builds statements for the given dialect,
+// a nil dialect means MySQL
+func New(dialect Dialect) *Builder {
+	if nil == dialect {
+		dialect = MySQL
+	}
+	return &Builder{dialect: dialect}
+}
+
+// Dialect returns the dialect of the Builder
+func (b *Builder) Dialect() Dialect {
+	return b.dialect
+}
+
+// Quote sets how b quotes the identifiers in the statements, default QuoteNone
+func (b *Builder) Quote(mode QuoteMode) *Builder {
+	b.quote = mode
+	return b
+}
+
+func (b *Builder) quoteIdent(ident string) (string, error) {
+	if QuoteNone == b.quote {
+		return ident, nil
+	}
+	return quoteExpr(b.dialect, ident, QuoteStrict == b.quote)
+}
+
+func (b *Builder) quoteList(list string, withDirection bool) (string, error) {
+	if QuoteNone == b.quote || "" == list {
+		return list, nil
+	}
+	return quoteList(b.dialect, list, QuoteStrict == b.quote, withDirection)
+}
+
+func (b *Builder) rebind(cond string, vals []interface{}, err error) (string, []interface{}, error) {
+	if nil != err {
+		return "", nil, err
+	}
+	return rebind(b.dialect, cond), vals, nil
+}
+
 // BuildSelect work as its name says.
 // supported operators including: =,in,>,>=,<,<=,<>,!=.
 // key without operator will be regarded as =.
@@ -63,6 +120,25 @@ type eleLimit struct {
 // the value of _having must be a map just like where but only support =,in,>,>=,<,<=,<>,!=
 // for more examples,see README.md or open a issue.
 func BuildSelect(table string, where map[string]interface{}, selectField []string) (cond string, vals []interface{}, err error) {
+	return defaultBuilder.BuildSelect(table, where, selectField)
+}
+
+// BuildSelect is the same as the package-level BuildSelect but in the dialect of b
+func (b *Builder) BuildSelect(table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	return b.rebind(b.buildSelectMap(table, where, selectField))
+}
+
+func (b *Builder) buildSelectMap(table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	stmt, err := b.resolveSelect(table, where, selectField)
+	if nil != err {
+		return "", nil, err
+	}
+	return b.buildSelect(stmt)
+}
+
+func (b *Builder) resolveSelect(table string, where map[string]interface{}, selectField []string) (stmt selectStmt, err error) {
+	var with []CTE
+	var joins []joinClause
 	var orderBy string
 	var limit *eleLimit
 	var groupBy string
@@ -119,25 +195,74 @@ func BuildSelect(table string, where map[string]interface{}, selectField []strin
 			return
 		}
 		lockMode = strings.TrimSpace(s)
-		if _, ok := allowedLockMode[lockMode]; !ok {
-			err = errNotAllowedLockMode
+		if _, err = b.dialect.Lock(lockMode); nil != err {
 			return
 		}
 	}
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	if val, ok := where["_with"]; ok {
+		if with, err = resolveWith(val); nil != err {
+			return
+		}
+	}
+	if val, ok := where["_join"]; ok {
+		js, ok := val.([]Join)
+		if !ok {
+			err = errJoinValueType
+			return
+		}
+		for _, j := range js {
+			join, err1 := b.resolveJoin(j)
+			if nil != err1 {
+				err = err1
+				return
+			}
+			joins = append(joins, join)
+		}
+	}
+	conditions, err := b.getWhereConditions(where, defaultIgnoreKeys)
 	if nil != err {
 		return
 	}
-	if having != nil {
-		havingCondition, err1 := getWhereConditions(having, defaultIgnoreKeys)
+	if val, ok := where["_keyset"]; ok {
+		if "" != orderBy {
+			err = errKeysetOrderBy
+			return
+		}
+		k, err1 := resolveKeyset(val)
+		if nil != err1 {
+			err = err1
+			return
+		}
+		cond, err1 := b.buildKeyset(k)
 		if nil != err1 {
 			err = err1
 			return
 		}
-		conditions = append(conditions, nilComparable(0))
-		conditions = append(conditions, havingCondition...)
+		if nil != cond {
+			conditions = append(conditions, cond)
+		}
+		orderBy = k.orderBy()
+	}
+	var havingConditions []Comparable
+	if having != nil {
+		havingConditions, err = b.getWhereConditions(having, defaultIgnoreKeys)
+		if nil != err {
+			return
+		}
 	}
-	return buildSelect(table, selectField, groupBy, orderBy, lockMode, limit, conditions...)
+	stmt = selectStmt{
+		with:     with,
+		table:    table,
+		joins:    joins,
+		fields:   selectField,
+		where:    conditions,
+		groupBy:  groupBy,
+		having:   havingConditions,
+		orderBy:  orderBy,
+		limit:    limit,
+		lockMode: lockMode,
+	}
+	return
 }
 
 func copyWhere(src map[string]interface{}) (target map[string]interface{}) {
@@ -170,6 +295,11 @@ func resolveHaving(having interface{}) (map[string]interface{}, error) {
 
 // BuildUpdate work as its name says
 func BuildUpdate(table string, where map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
+	return defaultBuilder.BuildUpdate(table, where, update)
+}
+
+// BuildUpdate is the same as the package-level BuildUpdate but in the dialect of b
+func (b *Builder) BuildUpdate(table string, where map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
 	var limit uint
 	if v, ok := where["_limit"]; ok {
 		switch val := v.(type) {
@@ -185,40 +315,67 @@ func BuildUpdate(table string, where map[string]interface{}, update map[string]i
 			return "", nil, errUpdateLimitType
 		}
 	}
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	conditions, err := b.getWhereConditions(where, defaultIgnoreKeys)
 	if nil != err {
 		return "", nil, err
 	}
-	return buildUpdate(table, update, limit, conditions...)
+	return b.rebind(b.buildUpdate(table, update, limit, conditions...))
 }
 
 // BuildDelete work as its name says
 func BuildDelete(table string, where map[string]interface{}) (string, []interface{}, error) {
-	conditions, err := getWhereConditions(where, defaultIgnoreKeys)
+	return defaultBuilder.BuildDelete(table, where)
+}
+
+// BuildDelete is the same as the package-level BuildDelete but in the dialect of b
+func (b *Builder) BuildDelete(table string, where map[string]interface{}) (string, []interface{}, error) {
+	conditions, err := b.getWhereConditions(where, defaultIgnoreKeys)
 	if nil != err {
 		return "", nil, err
 	}
-	return buildDelete(table, conditions...)
+	return b.rebind(b.buildDelete(table, conditions...))
 }
 
 // BuildInsert work as its name says
 func BuildInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
-	return buildInsert(table, data, commonInsert)
+	return defaultBuilder.BuildInsert(table, data)
+}
+
+// BuildInsert is the same as the package-level BuildInsert but in the dialect of b
+func (b *Builder) BuildInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(b.buildInsert(table, data, commonInsert))
 }
 
 // BuildInsertIgnore work as its name says
 func BuildInsertIgnore(table string, data []map[string]interface{}) (string, []interface{}, error) {
-	return buildInsert(table, data, ignoreInsert)
+	return defaultBuilder.BuildInsertIgnore(table, data)
+}
+
+// BuildInsertIgnore is the same as the package-level BuildInsertIgnore but in the dialect of b.
+// It's INSERT ... ON CONFLICT DO NOTHING in PostgreSQL and INSERT OR IGNORE in SQLite.
+func (b *Builder) BuildInsertIgnore(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(b.buildInsert(table, data, ignoreInsert))
 }
 
 // BuildReplaceInsert work as its name says
 func BuildReplaceInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
-	return buildInsert(table, data, replaceInsert)
+	return defaultBuilder.BuildReplaceInsert(table, data)
+}
+
+// BuildReplaceInsert is the same as the package-level BuildReplaceInsert but in the dialect of b
+func (b *Builder) BuildReplaceInsert(table string, data []map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(b.buildInsert(table, data, replaceInsert))
 }
 
 // BuildInsertOnDuplicateKey builds an INSERT ... ON DUPLICATE KEY UPDATE clause.
 func BuildInsertOnDuplicate(table string, data []map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
-	return buildInsertOnDuplicate(table, data, update)
+	return defaultBuilder.BuildInsertOnDuplicate(table, data, update)
+}
+
+// BuildInsertOnDuplicate is the same as the package-level BuildInsertOnDuplicate but in the dialect of b.
+// PostgreSQL requires a conflict target which this function can't express.
+func (b *Builder) BuildInsertOnDuplicate(table string, data []map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(b.buildInsertOnDuplicate(table, data, update))
 }
 
 func isStringInSlice(str string, arr []string) bool {
@@ -230,38 +387,34 @@ func isStringInSlice(str string, arr []string) bool {
 	return false
 }
 
-func getWhereConditions(where map[string]interface{}, ignoreKeys map[string]struct{}) ([]Comparable, error) {
+func (b *Builder) getWhereConditions(where map[string]interface{}, ignoreKeys map[string]struct{}) ([]Comparable, error) {
 	if len(where) == 0 {
 		return nil, nil
 	}
 	wms := &whereMapSet{}
 	var comparables []Comparable
+	var logicalKeys []string
+	var exists, notExists []*Subquery
 	var field, operator string
 	var err error
 	for key, val := range where {
 		if _, ok := ignoreKeys[key]; ok {
 			continue
 		}
-		if key == "_or" {
-			var (
-				orWheres          []map[string]interface{}
-				orWhereComparable []Comparable
-				ok                bool
-			)
-			if orWheres, ok = val.([]map[string]interface{}); !ok {
-				return nil, errOrValueType
+		if _, ok := logicalConnector(key); ok {
+			logicalKeys = append(logicalKeys, key)
+			continue
+		}
+		if key == "_exists" || key == "_not_exists" {
+			subs, err := resolveSubqueries(val)
+			if nil != err {
+				return nil, err
 			}
-			for _, orWhere := range orWheres {
-				if orWhere == nil {
-					continue
-				}
-				orNestWhere, err := getWhereConditions(orWhere, ignoreKeys)
-				if nil != err {
-					return nil, err
-				}
-				orWhereComparable = append(orWhereComparable, NestWhere(orNestWhere))
+			if key == "_exists" {
+				exists = append(exists, subs...)
+			} else {
+				notExists = append(notExists, subs...)
 			}
-			comparables = append(comparables, OrWhere(orWhereComparable))
 			continue
 		}
 		field, operator, err = splitKey(key, val)
@@ -269,22 +422,163 @@ func getWhereConditions(where map[string]interface{}, ignoreKeys map[string]stru
 			return nil, err
 		}
 		operator = strings.ToLower(operator)
-		if !isStringInSlice(operator, opOrder) {
+		if !isStringInSlice(operator, opOrder) || operator == opInExpr || operator == opNotInExpr {
 			return nil, ErrUnsupportedOperator
 		}
 		if _, ok := val.(NullType); ok {
 			operator = opNull
 		}
+		field, err = b.quoteIdent(field)
+		if nil != err {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case Col:
+			quoted, err := b.quoteIdent(string(v))
+			if nil != err {
+				return nil, err
+			}
+			val = Col(quoted)
+		case *Subquery:
+			if nil == v {
+				return nil, errNilSubquery
+			}
+		}
+		if e, ok := val.(expression); ok {
+			switch operator {
+			case opIn:
+				operator, val = opInExpr, inList(e)
+			case opNotIn:
+				operator, val = opNotInExpr, inList(e)
+			}
+		}
 		wms.add(operator, field, val)
 	}
+	// the order of map keys is random, sort them to make the result predictable
+	defaultSortAlgorithm(logicalKeys)
+	for _, key := range logicalKeys {
+		group, err := b.buildLogicalGroup(key, where[key], ignoreKeys)
+		if nil != err {
+			return nil, err
+		}
+		comparables = append(comparables, group)
+	}
 	whereComparables, err := buildWhereCondition(wms)
 	if nil != err {
 		return nil, err
 	}
 	comparables = append(comparables, whereComparables...)
+	if len(exists) > 0 {
+		comparables = append(comparables, existsComparable{subs: exists})
+	}
+	if len(notExists) > 0 {
+		comparables = append(comparables, existsComparable{subs: notExists, not: true})
+	}
 	return comparables, nil
 }
 
+// inList parenthesizes the expression after IN, such as Col("x") to (x).
+// A Subquery or a Raw already in parentheses like Expr("(SELECT id FROM t)") is kept.
+func inList(e expression) expression {
+	if _, ok := e.(*Subquery); ok {
+		return e
+	}
+	sql, args := e.expression()
+	if isParenthesized(sql) {
+		return e
+	}
+	return Raw{sql: "(" + sql + ")", args: args}
+}
+
+// isParenthesized reports whether the whole sql is in a pair of parentheses
+func isParenthesized(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	if !strings.HasPrefix(sql, "(") || !strings.HasSuffix(sql, ")") {
+		return false
+	}
+	// (a) OR (b) starts and ends with parentheses which aren't a pair
+	depth := 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if 0 == depth && i != len(sql)-1 {
+				return false
+			}
+		}
+	}
+	return 0 == depth
+}
+
+// logicalConnector returns the connector of the logical group keys:
+// _or, _and and their suffixed variants like _or_1, _and_2
+func logicalConnector(key string) (string, bool) {
+	switch {
+	case key == "_or" || strings.HasPrefix(key, "_or_"):
+		return "OR", true
+	case key == "_and" || strings.HasPrefix(key, "_and_"):
+		return "AND", true
+	}
+	return "", false
+}
+
+// buildLogicalGroup builds the value of a logical group key, every element of
+// the value is a where map whose conditions are joined by AND, and then the
+// elements are joined by the connector of the key
+func (b *Builder) buildLogicalGroup(key string, val interface{}, ignoreKeys map[string]struct{}) (Comparable, error) {
+	connector, _ := logicalConnector(key)
+	groups, ok := val.([]map[string]interface{})
+	if !ok {
+		if "OR" == connector {
+			return nil, errOrValueType
+		}
+		return nil, errAndValueType
+	}
+	var groupComparables []Comparable
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		nested, err := b.getWhereConditions(group, ignoreKeys)
+		if nil != err {
+			return nil, err
+		}
+		groupComparables = append(groupComparables, NestWhere(nested))
+	}
+	if "OR" == connector {
+		return OrWhere(groupComparables), nil
+	}
+	return NestWhere(groupComparables), nil
+}
+
+func resolveSubqueries(val interface{}) ([]*Subquery, error) {
+	var subs []*Subquery
+	switch v := val.(type) {
+	case *Subquery:
+		subs = []*Subquery{v}
+	case []*Subquery:
+		subs = v
+	default:
+		return nil, errExistsValueType
+	}
+	for _, sub := range subs {
+		if nil == sub {
+			return nil, errNilSubquery
+		}
+	}
+	return subs, nil
+}
+
 const (
 	opEq         = "="
 	opNe1        = "!="
@@ -300,7 +594,9 @@ const (
 	opBetween    = "between"
 	opNotBetween = "not between"
 	// special
-	opNull = "null"
+	opNull      = "null"
+	opInExpr    = "in expression"
+	opNotInExpr = "not in expression"
 )
 
 type compareProducer func(m map[string]interface{}) (Comparable, error)
@@ -364,9 +660,15 @@ var op2Comparable = map[string]compareProducer{
 	opNull: func(m map[string]interface{}) (Comparable, error) {
 		return nullCompareble(m), nil
 	},
+	opInExpr: func(m map[string]interface{}) (Comparable, error) {
+		return expressionIn{m: m}, nil
+	},
+	opNotInExpr: func(m map[string]interface{}) (Comparable, error) {
+		return expressionIn{m: m, not: true}, nil
+	},
 }
 
-var opOrder = []string{opEq, opIn, opNe1, opNe2, opNotIn, opGt, opGte, opLt, opLte, opLike, opNotLike, opBetween, opNotBetween, opNull}
+var opOrder = []string{opEq, opIn, opNe1, opNe2, opNotIn, opGt, opGte, opLt, opLte, opLike, opNotLike, opBetween, opNotBetween, opNull, opInExpr, opNotInExpr}
 
 func buildWhereCondition(mapSet *whereMapSet) ([]Comparable, error) {
 	var cpArr []Comparable
@@ -461,6 +763,15 @@ var searchHandle = regexp.MustCompile(`{{\S+?}}`)
 
 // NamedQuery is used for expressing complex query
 func NamedQuery(sql string, data map[string]interface{}) (string, []interface{}, error) {
+	return defaultBuilder.NamedQuery(sql, data)
+}
+
+// NamedQuery is the same as the package-level NamedQuery but in the dialect of b
+func (b *Builder) NamedQuery(sql string, data map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(namedQuery(sql, data))
+}
+
+func namedQuery(sql string, data map[string]interface{}) (string, []interface{}, error) {
 	length := len(data)
 	if length == 0 {
 		return sql, nil, nil
@@ -474,6 +785,11 @@ func NamedQuery(sql string, data map[string]interface{}) (string, []interface{},
 			err = fmt.Errorf("%s not found", paramName)
 			return ""
 		}
+		if e, ok := val.(expression); ok {
+			exprString, exprVals := e.expression()
+			vals = append(vals, exprVals...)
+			return exprString
+		}
 		v := reflect.ValueOf(val)
 		if v.Type().Kind() != reflect.Slice {
 			vals = append(vals, val)
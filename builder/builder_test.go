@@ -1318,3 +1318,213 @@ func TestInsertOnDuplicate(t *testing.T) {
 	ass.Equal("INSERT INTO tb (a,b,c) VALUES (?,?,?) ON DUPLICATE KEY UPDATE c=?", cond)
 	ass.Equal([]interface{}{1, 2, 3, 4}, vals)
 }
+
+func TestBuildSelectJoin(t *testing.T) {
+	var data = []struct {
+		where map[string]interface{}
+		cond  string
+		vals  []interface{}
+		err   error
+	}{
+		{
+			where: map[string]interface{}{
+				"_join": []Join{
+					{
+						Type:  LeftJoin,
+						Table: "orders o",
+						On: map[string]interface{}{
+							"o.uid":      Col("u.id"),
+							"o.status >": 2,
+							"o.deleted":  IsNull,
+						},
+					},
+					{
+						Type:  InnerJoin,
+						Table: "city c",
+						On: map[string]interface{}{
+							"c.id":       Col("u.city_id"),
+							"c.level in": []int{1, 2},
+						},
+					},
+				},
+				"u.age >":  18,
+				"_groupby": "u.id",
+				"_having": map[string]interface{}{
+					"total >": 100,
+				},
+				"_orderby": "total desc",
+				"_limit":   []uint{0, 10},
+			},
+			cond: "SELECT u.id,sum(o.price) as total FROM user u LEFT JOIN orders o ON (o.uid=u.id AND o.status>? AND o.deleted IS NULL) INNER JOIN city c ON (c.id=u.city_id AND c.level IN (?,?)) WHERE (u.age>?) GROUP BY u.id HAVING (total>?) ORDER BY total desc LIMIT ?,?",
+			vals: []interface{}{2, 1, 2, 18, 100, 0, 10},
+		},
+		{
+			where: map[string]interface{}{
+				"_join": Join{Type: LeftJoin, Table: "orders o"},
+			},
+			err: errJoinValueType,
+		},
+		{
+			where: map[string]interface{}{
+				"_join": []Join{{Type: LeftJoin, Table: "orders o"}},
+			},
+			err: errJoinOnEmpty,
+		},
+		{
+			where: map[string]interface{}{
+				"_join": []Join{{Type: "OUTER JOIN", Table: "orders o", On: map[string]interface{}{"o.uid": Col("u.id")}}},
+			},
+			err: errJoinType,
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		cond, vals, err := BuildSelect("user u", tc.where, []string{"u.id", "sum(o.price) as total"})
+		ass.Equal(tc.err, err)
+		ass.Equal(tc.cond, cond)
+		ass.Equal(tc.vals, vals)
+	}
+	cond, vals, err := New(PostgreSQL).Quote(QuoteAll).BuildSelect("user u", map[string]interface{}{
+		"_join": []Join{{Type: RightJoin, Table: "orders o", On: map[string]interface{}{"o.uid": Col("u.id"), "o.status": 1}}},
+		"u.age": 18,
+	}, []string{"u.name", "o.price"})
+	ass.NoError(err)
+	ass.Equal(`SELECT "u"."name","o"."price" FROM "user" AS "u" RIGHT JOIN "orders" AS "o" ON ("o"."status"=$1 AND "o"."uid"="u"."id") WHERE ("u"."age"=$2)`, cond)
+	ass.Equal([]interface{}{1, 18}, vals)
+}
+
+func TestBuildLogicalGroups(t *testing.T) {
+	var data = []struct {
+		where map[string]interface{}
+		cond  string
+		vals  []interface{}
+		err   error
+	}{
+		{
+			where: map[string]interface{}{
+				"status": 1,
+				"_or_1": []map[string]interface{}{
+					{"city": "Beijing"},
+					{"city": "Shanghai"},
+				},
+				"_or_2": []map[string]interface{}{
+					{"age <": 18},
+					{"age >": 60},
+				},
+			},
+			cond: "SELECT * FROM tb WHERE (((city=?) OR (city=?)) AND ((age<?) OR (age>?)) AND status=?)",
+			vals: []interface{}{"Beijing", "Shanghai", 18, 60, 1},
+		},
+		{
+			where: map[string]interface{}{
+				"_or": []map[string]interface{}{
+					{
+						"_and": []map[string]interface{}{
+							{"_or": []map[string]interface{}{{"a": 1}, {"b": 2}}},
+							{"_or": []map[string]interface{}{{"c": 3}, {"d": 4}}},
+						},
+					},
+					{
+						"e":     5,
+						"_or_x": []map[string]interface{}{{"f": 6}, {"g in": []int{7, 8}}},
+					},
+				},
+			},
+			cond: "SELECT * FROM tb WHERE (((((((a=?) OR (b=?))) AND (((c=?) OR (d=?))))) OR (((f=?) OR (g IN (?,?))) AND e=?)))",
+			vals: []interface{}{1, 2, 3, 4, 6, 7, 8, 5},
+		},
+		{
+			where: map[string]interface{}{
+				"a":     1,
+				"_or":   []map[string]interface{}{},
+				"_and_": []map[string]interface{}{nil, {}},
+			},
+			cond: "SELECT * FROM tb WHERE (a=?)",
+			vals: []interface{}{1},
+		},
+		{
+			where: map[string]interface{}{
+				"_and": map[string]interface{}{"a": 1},
+			},
+			err: errAndValueType,
+		},
+		{
+			where: map[string]interface{}{
+				"_or_2": map[string]interface{}{"a": 1},
+			},
+			err: errOrValueType,
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		cond, vals, err := BuildSelect("tb", tc.where, nil)
+		ass.Equal(tc.err, err)
+		ass.Equal(tc.cond, cond)
+		ass.Equal(tc.vals, vals)
+	}
+}
+
+func TestRawExpression(t *testing.T) {
+	ass := assert.New(t)
+	cond, vals, err := BuildUpdate("tb", map[string]interface{}{
+		"id":           1,
+		"updated_at <": Expr("NOW() - INTERVAL ? DAY", 7),
+	}, map[string]interface{}{
+		"counter":    Expr("counter+?", 2),
+		"name":       "deen",
+		"updated_at": Expr("NOW()"),
+	})
+	ass.NoError(err)
+	ass.Equal("UPDATE tb SET counter=counter+?,name=?,updated_at=NOW() WHERE (id=? AND updated_at<NOW() - INTERVAL ? DAY)", cond)
+	ass.Equal([]interface{}{2, "deen", 1, 7}, vals)
+
+	cond, vals, err = BuildInsert("tb", []map[string]interface{}{
+		{"name": "deen", "created_at": Expr("NOW()"), "score": Expr("?*?", 3, 4)},
+		{"name": "tony", "created_at": Expr("FROM_UNIXTIME(?)", 1600000000), "score": 5},
+	})
+	ass.NoError(err)
+	ass.Equal("INSERT INTO tb (created_at,name,score) VALUES (NOW(),?,?*?),(FROM_UNIXTIME(?),?,?)", cond)
+	ass.Equal([]interface{}{"deen", 3, 4, 1600000000, "tony", 5}, vals)
+
+	cond, vals, err = BuildInsertOnDuplicate("tb", []map[string]interface{}{
+		{"id": 1, "total": 10},
+	}, map[string]interface{}{
+		"total":      Expr("total+VALUES(total)"),
+		"updated_at": Expr("NOW()"),
+	})
+	ass.NoError(err)
+	ass.Equal("INSERT INTO tb (id,total) VALUES (?,?) ON DUPLICATE KEY UPDATE total=total+VALUES(total),updated_at=NOW()", cond)
+	ass.Equal([]interface{}{1, 10}, vals)
+
+	cond, vals, err = New(PostgreSQL).BuildSelect("tb", map[string]interface{}{
+		"id in":        Expr("(SELECT uid FROM vip WHERE level>?)", 3),
+		"id not in":    []int{1},
+		"name like":    Expr("CONCAT(?, '%')", "de"),
+		"created_at >": Expr("NOW() - INTERVAL '1 day'"),
+	}, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM tb WHERE (id NOT IN ($1) AND created_at>NOW() - INTERVAL '1 day' AND name LIKE CONCAT($2, '%') AND id IN (SELECT uid FROM vip WHERE level>$3))", cond)
+	ass.Equal([]interface{}{1, "de", 3}, vals)
+
+	cond, vals, err = BuildSelect("tb", map[string]interface{}{
+		"a in":     Col("x"),
+		"b not in": Expr("?,?", 1, 2),
+		"c in":     Expr("(SELECT 1) UNION (SELECT ?)", 3),
+	}, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM tb WHERE (a IN (x) AND c IN ((SELECT 1) UNION (SELECT ?)) AND b NOT IN (?,?))", cond, "the expressions after IN are parenthesized")
+	ass.Equal([]interface{}{3, 1, 2}, vals)
+
+	cond, vals, err = Select("id").Field(Expr("IF(age>?,1,0) AS adult", 18)).From("tb").Where(Eq{"city": "Beijing"}).Build()
+	ass.NoError(err)
+	ass.Equal("SELECT id,IF(age>?,1,0) AS adult FROM tb WHERE (city=?)", cond)
+	ass.Equal([]interface{}{18, "Beijing"}, vals)
+
+	cond, vals, err = NamedQuery("select * from tb where created_at>{{t}} and id={{id}}", map[string]interface{}{
+		"t":  Expr("NOW() - INTERVAL ? DAY", 1),
+		"id": 2,
+	})
+	ass.NoError(err)
+	ass.Equal("select * from tb where created_at>NOW() - INTERVAL ? DAY and id=?", cond)
+	ass.Equal([]interface{}{1, 2}, vals)
+}
@@ -0,0 +1,159 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errCompoundOperator = errors.New("[builder] unknown set operator")
+
+// SetOperator combines the results of SELECT statements
+type SetOperator string
+
+const (
+	// Union removes the duplicate rows
+	Union SetOperator = "UNION"
+	// UnionAll keeps the duplicate rows
+	UnionAll SetOperator = "UNION ALL"
+	// Intersect returns the rows in both results
+	Intersect SetOperator = "INTERSECT"
+	// IntersectAll is the same as Intersect but keeps the duplicate rows
+	IntersectAll SetOperator = "INTERSECT ALL"
+	// Except returns the rows in the left result but not in the right one
+	Except SetOperator = "EXCEPT"
+	// ExceptAll is the same as Except but keeps the duplicate rows
+	ExceptAll SetOperator = "EXCEPT ALL"
+)
+
+type compoundPart struct {
+	op  SetOperator
+	sub *Subquery
+}
+
+// CompoundBuilder combines SELECT statements by UNION, INTERSECT and EXCEPT.
+// Each statement is parenthesized so that it could have its own ORDER BY and LIMIT,
+// and the statements are combined from left to right in the order they're added.
+type CompoundBuilder struct {
+	builder *Builder
+	first   *Subquery
+	parts   []compoundPart
+	orderBy string
+	offset  uint
+	count   *uint
+}
+
+// Compose starts a MySQL compound statement from the first SELECT statement:
+//
+//	Compose(a).Union(b).UnionAll(c).OrderBy("id").Limit(10).Build()
+func Compose(first *Subquery) *CompoundBuilder {
+	return defaultBuilder.Compose(first)
+}
+
+// Compose is the same as the package-level Compose but in the dialect of b
+func (b *Builder) Compose(first *Subquery) *CompoundBuilder {
+	return &CompoundBuilder{builder: b, first: first}
+}
+
+// Combine adds a SELECT statement with the set operator op
+func (c *CompoundBuilder) Combine(op SetOperator, sub *Subquery) *CompoundBuilder {
+	c.parts = append(c.parts, compoundPart{op: op, sub: sub})
+	return c
+}
+
+// Union adds a SELECT statement with UNION
+func (c *CompoundBuilder) Union(sub *Subquery) *CompoundBuilder {
+	return c.Combine(Union, sub)
+}
+
+// UnionAll adds a SELECT statement with UNION ALL
+func (c *CompoundBuilder) UnionAll(sub *Subquery) *CompoundBuilder {
+	return c.Combine(UnionAll, sub)
+}
+
+// Intersect adds a SELECT statement with INTERSECT
+func (c *CompoundBuilder) Intersect(sub *Subquery) *CompoundBuilder {
+	return c.Combine(Intersect, sub)
+}
+
+// Except adds a SELECT statement with EXCEPT
+func (c *CompoundBuilder) Except(sub *Subquery) *CompoundBuilder {
+	return c.Combine(Except, sub)
+}
+
+// OrderBy sets the ORDER BY clause of the combined result, the items must be
+// the columns of the result: OrderBy("age DESC", "id")
+func (c *CompoundBuilder) OrderBy(items ...string) *CompoundBuilder {
+	c.orderBy = strings.Join(items, ",")
+	return c
+}
+
+// Limit sets the max number of rows of the combined result
+func (c *CompoundBuilder) Limit(count uint) *CompoundBuilder {
+	c.count = &count
+	return c
+}
+
+// Offset sets the number of rows of the combined result to skip, it requires Limit
+func (c *CompoundBuilder) Offset(offset uint) *CompoundBuilder {
+	c.offset = offset
+	return c
+}
+
+func (c *CompoundBuilder) build() (string, []interface{}, error) {
+	if nil == c.first {
+		return "", nil, errNilSubquery
+	}
+	if nil == c.count && c.offset > 0 {
+		return "", nil, errSelectOffsetNoLimit
+	}
+	cond, vals := c.first.Build()
+	var last SetOperator
+	for _, part := range c.parts {
+		if nil == part.sub {
+			return "", nil, errNilSubquery
+		}
+		switch part.op {
+		case Union, UnionAll, Intersect, IntersectAll, Except, ExceptAll:
+		default:
+			return "", nil, errCompoundOperator
+		}
+		operand, err := c.builder.dialect.Compound(part.op)
+		if nil != err {
+			return "", nil, err
+		}
+		// the left side is wrapped again when the operator changes, since INTERSECT
+		// binds tighter than UNION and EXCEPT in some databases
+		if part.op != last {
+			cond = fmt.Sprintf(operand, cond)
+		}
+		subCond, subVals := part.sub.Build()
+		cond += " " + string(part.op) + " " + fmt.Sprintf(operand, subCond)
+		vals = append(vals, subVals...)
+		last = part.op
+	}
+	orderBy, err := c.builder.quoteList(c.orderBy, true)
+	if nil != err {
+		return "", nil, err
+	}
+	if "" != orderBy {
+		cond += " ORDER BY " + orderBy
+	}
+	if nil != c.count {
+		limitString, limitVals := c.builder.dialect.Limit(c.offset, *c.count)
+		cond += limitString
+		vals = append(vals, limitVals...)
+	}
+	return cond, vals, nil
+}
+
+// Build returns the statement and its arguments
+func (c *CompoundBuilder) Build() (string, []interface{}, error) {
+	return c.builder.rebind(c.build())
+}
+
+// Subquery returns the statement as a Subquery, such as the Query of a CTE
+// or a derived table of BuildSelectFrom
+func (c *CompoundBuilder) Subquery() (*Subquery, error) {
+	return NewSubquery(c.build())
+}
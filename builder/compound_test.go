@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	ass := assert.New(t)
+	active, err := NewSubquery(BuildSelect("user", map[string]interface{}{"status": 1, "_orderby": "id DESC", "_limit": []uint{5}}, []string{"id", "name"}))
+	ass.NoError(err)
+	vip, err := NewSubquery(BuildSelect("vip", map[string]interface{}{"level >=": 3}, []string{"id", "name"}))
+	ass.NoError(err)
+	banned, err := NewSubquery("SELECT id,name FROM banned WHERE reason=?", []interface{}{"spam"}, nil)
+	ass.NoError(err)
+
+	cond, vals, err := Compose(active).Union(vip).OrderBy("name", "id DESC").Limit(10).Offset(20).Build()
+	ass.NoError(err)
+	ass.Equal("(SELECT id,name FROM user WHERE (status=?) ORDER BY id DESC LIMIT ?,?) UNION (SELECT id,name FROM vip WHERE (level>=?)) ORDER BY name,id DESC LIMIT ?,?", cond)
+	ass.Equal([]interface{}{1, 0, 5, 3, 20, 10}, vals, "args are merged in order")
+
+	cond, vals, err = Compose(active).UnionAll(vip).UnionAll(banned).Except(banned).Build()
+	ass.NoError(err)
+	ass.Equal("((SELECT id,name FROM user WHERE (status=?) ORDER BY id DESC LIMIT ?,?) UNION ALL (SELECT id,name FROM vip WHERE (level>=?)) UNION ALL (SELECT id,name FROM banned WHERE reason=?)) "+
+		"EXCEPT (SELECT id,name FROM banned WHERE reason=?)", cond, "combined from left to right")
+	ass.Equal([]interface{}{1, 0, 5, 3, "spam", "spam"}, vals)
+
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).Compose(vip).Intersect(banned).OrderBy("name").Limit(10).Build()
+	ass.NoError(err)
+	ass.Equal(`(SELECT id,name FROM vip WHERE (level>=$1)) INTERSECT (SELECT id,name FROM banned WHERE reason=$2) ORDER BY "name" LIMIT $3 OFFSET $4`, cond)
+	ass.Equal([]interface{}{3, "spam", 10, 0}, vals)
+
+	cond, vals, err = New(SQLite).Compose(vip).Except(banned).Union(vip).Build()
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM (SELECT * FROM (SELECT id,name FROM vip WHERE (level>=?)) EXCEPT SELECT * FROM (SELECT id,name FROM banned WHERE reason=?)) "+
+		"UNION SELECT * FROM (SELECT id,name FROM vip WHERE (level>=?))", cond)
+	ass.Equal([]interface{}{3, "spam", 3}, vals)
+
+	sub, err := Compose(vip).Union(banned).Subquery()
+	ass.NoError(err)
+	cond, vals, err = New(PostgreSQL).Select("count(*)").FromSubquery(sub, "t").Build()
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM ((SELECT id,name FROM vip WHERE (level>=$1)) UNION (SELECT id,name FROM banned WHERE reason=$2)) AS t", cond)
+	ass.Equal([]interface{}{3, "spam"}, vals)
+}
+
+func TestComposeError(t *testing.T) {
+	ass := assert.New(t)
+	vip, err := NewSubquery("SELECT id FROM vip", nil, nil)
+	ass.NoError(err)
+	_, _, err = Compose(nil).Union(vip).Build()
+	ass.Equal(errNilSubquery, err)
+	_, _, err = Compose(vip).Union(nil).Build()
+	ass.Equal(errNilSubquery, err)
+	_, _, err = Compose(vip).Combine("MINUS", vip).Build()
+	ass.Equal(errCompoundOperator, err)
+	_, _, err = Compose(vip).Union(vip).Offset(10).Build()
+	ass.Equal(errSelectOffsetNoLimit, err)
+	_, _, err = New(SQLite).Compose(vip).Combine(ExceptAll, vip).Build()
+	ass.True(errors.Is(err, ErrUnsupportedSyntax))
+	_, err = New(SQLite).Compose(vip).Combine(IntersectAll, vip).Subquery()
+	ass.True(errors.Is(err, ErrUnsupportedSyntax))
+}
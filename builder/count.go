@@ -0,0 +1,64 @@
+package builder
+
+import "strings"
+
+// countIgnoreKeys are the keys of a where map which don't affect the number of rows
+var countIgnoreKeys = []string{"_orderby", "_limit", "_lockMode", "_keyset"}
+
+// BuildCount builds the statement counting the rows which BuildSelect with the same where
+// would return without _orderby, _limit, _lockMode and _keyset, so that a page and its total
+// could share the where map.
+// selectField are the fields of the page, they matter if where has _groupby or the fields
+// start with DISTINCT, then the rows of the page are counted by a derived table:
+// SELECT count(*) FROM (SELECT selectField ... GROUP BY ... HAVING ...) AS t
+func BuildCount(table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	return defaultBuilder.BuildCount(table, where, selectField)
+}
+
+// BuildCount is the same as the package-level BuildCount but in the dialect of b
+func (b *Builder) BuildCount(table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	return b.rebind(b.buildCount(table, where, selectField))
+}
+
+func (b *Builder) buildCount(table string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	where = copyWhere(where)
+	for _, key := range countIgnoreKeys {
+		delete(where, key)
+	}
+	stmt, err := b.resolveSelect(table, where, selectField)
+	if nil != err {
+		return "", nil, err
+	}
+	count := []Raw{Expr("count(*)")}
+	if "" == stmt.groupBy && !isDistinct(selectField) {
+		stmt.fields = nil
+		stmt.exprFields = count
+		return b.buildSelect(stmt)
+	}
+	// the WITH clause stays outside so that it's still the beginning of the statement
+	with := stmt.with
+	stmt.with = nil
+	if len(stmt.fields) == 0 {
+		stmt.exprFields = []Raw{Expr("1")}
+	}
+	groups, err := NewSubquery(b.buildSelect(stmt))
+	if nil != err {
+		return "", nil, err
+	}
+	return b.buildSelect(selectStmt{
+		with:       with,
+		from:       groups,
+		table:      "t",
+		exprFields: count,
+	})
+}
+
+// isDistinct reports whether the first field starts with DISTINCT, like DISTINCT a or distinct(a)
+func isDistinct(selectField []string) bool {
+	if len(selectField) == 0 {
+		return false
+	}
+	field := strings.TrimSpace(selectField[0])
+	return len(field) > 8 && strings.EqualFold(field[:8], "DISTINCT") &&
+		(field[8] == '(' || field[8] == ' ' || field[8] == '\t' || field[8] == '\n')
+}
@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCount(t *testing.T) {
+	ass := assert.New(t)
+	where := map[string]interface{}{
+		"status":    1,
+		"age >":     18,
+		"_orderby":  "id DESC",
+		"_limit":    []uint{20, 10},
+		"_lockMode": "exclusive",
+	}
+	cond, vals, err := BuildCount("user", where, []string{"id", "name"})
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM user WHERE (status=? AND age>?)", cond)
+	ass.Equal([]interface{}{1, 18}, vals)
+	ass.Len(where, 5, "where is untouched")
+
+	cond, vals, err = BuildCount("user", map[string]interface{}{
+		"_keyset": Keyset{Keys: []SortKey{{Column: "id"}}, After: []interface{}{100}},
+		"_join":   []Join{{Type: LeftJoin, Table: "vip v", On: map[string]interface{}{"v.uid": Col("user.id")}}},
+	}, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM user LEFT JOIN vip v ON (v.uid=user.id)", cond)
+	ass.Empty(vals)
+
+	paid, err := NewSubquery("SELECT uid FROM orders WHERE status=?", []interface{}{"paid"}, nil)
+	ass.NoError(err)
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).BuildCount("orders", map[string]interface{}{
+		"_with":    []CTE{{Name: "p", Query: paid}},
+		"uid in":   Expr("(SELECT uid FROM p)"),
+		"_groupby": "uid",
+		"_having":  map[string]interface{}{"count(*) >": 2},
+		"_orderby": "uid",
+		"_limit":   []uint{10},
+	}, nil)
+	ass.NoError(err)
+	ass.Equal(`WITH "p" AS (SELECT uid FROM orders WHERE status=$1) SELECT count(*) FROM (SELECT 1 FROM "orders" WHERE ("uid" IN (SELECT uid FROM p)) GROUP BY "uid" HAVING (count(*)>$2)) AS "t"`, cond)
+	ass.Equal([]interface{}{"paid", 2}, vals)
+
+	cond, vals, err = BuildCount("orders", map[string]interface{}{
+		"_groupby": "uid",
+		"_having":  map[string]interface{}{"total >": 100},
+		"_orderby": "total DESC",
+	}, []string{"uid", "sum(price) as total"})
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM (SELECT uid,sum(price) as total FROM orders GROUP BY uid HAVING (total>?)) AS t", cond, "HAVING may refer to the fields")
+	ass.Equal([]interface{}{100}, vals)
+
+	cond, vals, err = BuildCount("user", map[string]interface{}{"age >": 18, "_limit": []uint{10}}, []string{"DISTINCT city"})
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM (SELECT DISTINCT city FROM user WHERE (age>?)) AS t", cond)
+	ass.Equal([]interface{}{18}, vals)
+
+	cond, _, err = BuildCount("user", nil, []string{"distinct(city)"})
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM (SELECT distinct(city) FROM user) AS t", cond)
+	cond, _, err = BuildCount("user", nil, []string{"distinction"})
+	ass.NoError(err)
+	ass.Equal("SELECT count(*) FROM user", cond)
+
+	_, _, err = BuildCount("user", map[string]interface{}{"_groupby": 1}, nil)
+	ass.Equal(errGroupByValueType, err)
+}
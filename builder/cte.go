@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errWithValueType = errors.New(`[builder] the value of "_with" must be of []CTE type`)
+	errCTEName       = errors.New("[builder] the name of a CTE can't be empty")
+)
+
+// CTE is a common table expression of the WITH clause:
+// WITH Name (Columns) AS (Query).
+// Query is built by NewSubquery, either from BuildSelect or from raw SQL with its own args.
+type CTE struct {
+	Name string
+	// Columns is optional
+	Columns []string
+	Query   *Subquery
+	// Recursive makes the clause WITH RECURSIVE so that Query could refer to Name,
+	// it affects all the CTEs of the clause
+	Recursive bool
+}
+
+func resolveWith(val interface{}) ([]CTE, error) {
+	ctes, ok := val.([]CTE)
+	if !ok {
+		return nil, errWithValueType
+	}
+	for _, cte := range ctes {
+		if "" == strings.TrimSpace(cte.Name) {
+			return nil, errCTEName
+		}
+		if nil == cte.Query {
+			return nil, errNilSubquery
+		}
+	}
+	return ctes, nil
+}
+
+// buildWith returns the WITH clause followed by a space, its args come before the ones of the statement
+func (b *Builder) buildWith(ctes []CTE) (string, []interface{}, error) {
+	if len(ctes) == 0 {
+		return "", nil, nil
+	}
+	var vals []interface{}
+	recursive := false
+	parts := make([]string, 0, len(ctes))
+	for _, cte := range ctes {
+		recursive = recursive || cte.Recursive
+		name, err := b.quoteIdent(cte.Name)
+		if nil != err {
+			return "", nil, err
+		}
+		if len(cte.Columns) > 0 {
+			columns := make([]string, len(cte.Columns))
+			for i, column := range cte.Columns {
+				if columns[i], err = b.quoteIdent(column); nil != err {
+					return "", nil, err
+				}
+			}
+			name += " (" + strings.Join(columns, ",") + ")"
+		}
+		query, queryVals := cte.Query.expression()
+		parts = append(parts, name+" AS "+query)
+		vals = append(vals, queryVals...)
+	}
+	keyword := "WITH "
+	if recursive {
+		keyword = "WITH RECURSIVE "
+	}
+	return keyword + strings.Join(parts, ",") + " ", vals, nil
+}
+
+// With adds CTEs to the WITH clause of the statement
+func (s *SelectBuilder) With(ctes ...CTE) *SelectBuilder {
+	if _, err := resolveWith(ctes); nil != err {
+		s.err = err
+	}
+	s.stmt.with = append(s.stmt.with, ctes...)
+	return s
+}
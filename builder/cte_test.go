@@ -0,0 +1,85 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSelectWith(t *testing.T) {
+	ass := assert.New(t)
+	paid, err := NewSubquery(BuildSelect("orders", map[string]interface{}{
+		"status":   "paid",
+		"_groupby": "uid",
+	}, []string{"uid", "sum(price) AS total"}))
+	ass.NoError(err)
+	vip, err := NewSubquery("SELECT uid FROM vip WHERE level>=?", []interface{}{3}, nil)
+	ass.NoError(err)
+	where := map[string]interface{}{
+		"_with": []CTE{
+			{Name: "paid", Query: paid},
+			{Name: "v", Columns: []string{"uid"}, Query: vip},
+		},
+		"_join": []Join{
+			{Type: InnerJoin, Table: "paid p", On: map[string]interface{}{"p.uid": Col("u.id"), "p.total >": 100}},
+		},
+		"u.id in": Expr("(SELECT uid FROM v)"),
+		"u.age >": 18,
+		"_limit":  []uint{10},
+	}
+	cond, vals, err := BuildSelect("user u", where, []string{"u.name", "p.total"})
+	ass.NoError(err)
+	ass.Equal("WITH paid AS (SELECT uid,sum(price) AS total FROM orders WHERE (status=?) GROUP BY uid),v (uid) AS (SELECT uid FROM vip WHERE level>=?) "+
+		"SELECT u.name,p.total FROM user u INNER JOIN paid p ON (p.uid=u.id AND p.total>?) WHERE (u.age>? AND u.id IN (SELECT uid FROM v)) LIMIT ?,?", cond)
+	ass.Equal([]interface{}{"paid", 3, 100, 18, 0, 10}, vals, "args of the CTEs come first")
+
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).BuildSelect("v", map[string]interface{}{
+		"_with": []CTE{{Name: "v", Columns: []string{"uid"}, Query: vip}},
+		"uid >": 1,
+	}, []string{"uid"})
+	ass.NoError(err)
+	ass.Equal(`WITH "v" ("uid") AS (SELECT uid FROM vip WHERE level>=$1) SELECT "uid" FROM "v" WHERE ("uid">$2)`, cond)
+	ass.Equal([]interface{}{3, 1}, vals)
+}
+
+func TestBuildSelectWithRecursive(t *testing.T) {
+	ass := assert.New(t)
+	tree, err := NewSubquery("SELECT id,parent_id,name FROM category WHERE id=? "+
+		"UNION ALL SELECT c.id,c.parent_id,c.name FROM category c INNER JOIN tree t ON c.parent_id=t.id", []interface{}{1}, nil)
+	ass.NoError(err)
+	counts, err := New(MySQL).Subquery("product", map[string]interface{}{"_groupby": "category_id"}, []string{"category_id", "count(*) AS total"})
+	ass.NoError(err)
+	cond, vals, err := New(PostgreSQL).Select("t.name", "c.total").
+		With(CTE{Name: "tree", Query: tree, Recursive: true}, CTE{Name: "counts", Query: counts}).
+		From("tree t").
+		Join(LeftJoin, "counts c", Eq{"c.category_id": Col("t.id")}).
+		Where(Gt{"c.total": 0}).
+		Build()
+	ass.NoError(err)
+	ass.Equal("WITH RECURSIVE tree AS (SELECT id,parent_id,name FROM category WHERE id=$1 UNION ALL SELECT c.id,c.parent_id,c.name FROM category c INNER JOIN tree t ON c.parent_id=t.id),"+
+		"counts AS (SELECT category_id,count(*) AS total FROM product GROUP BY category_id) "+
+		"SELECT t.name,c.total FROM tree t LEFT JOIN counts c ON (c.category_id=t.id) WHERE (c.total>$2)", cond)
+	ass.Equal([]interface{}{1, 0}, vals)
+}
+
+func TestBuildSelectWithError(t *testing.T) {
+	ass := assert.New(t)
+	sub, err := NewSubquery("SELECT 1", nil, nil)
+	ass.NoError(err)
+	var data = []struct {
+		with interface{}
+		err  error
+	}{
+		{[]*Subquery{sub}, errWithValueType},
+		{[]CTE{{Name: " ", Query: sub}}, errCTEName},
+		{[]CTE{{Name: "t"}}, errNilSubquery},
+	}
+	for _, tc := range data {
+		_, _, err := BuildSelect("t", map[string]interface{}{"_with": tc.with}, nil)
+		ass.Equal(tc.err, err)
+	}
+	_, _, err = Select().With(CTE{Name: "t"}).From("t").Build()
+	ass.Equal(errNilSubquery, err)
+	_, _, err = New(MySQL).Quote(QuoteStrict).BuildSelect("t", map[string]interface{}{"_with": []CTE{{Name: "t`", Query: sub}}}, nil)
+	ass.Error(err)
+}
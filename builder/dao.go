@@ -11,11 +11,8 @@ var (
 	errInsertDataNotMatch = errors.New("insert data not match")
 	errInsertNullData     = errors.New("insert null data")
 	errOrderByParam       = errors.New("order param only should be ASC or DESC")
-
-	allowedLockMode = map[string]string{
-		"share":     " LOCK IN SHARE MODE",
-		"exclusive": " FOR UPDATE",
-	}
+	errJoinType           = errors.New("[builder] the type of join must be one of InnerJoin, LeftJoin and RightJoin")
+	errJoinOnEmpty        = errors.New("[builder] the on condition of join can't be empty")
 )
 
 //the order of a map is unpredicatable so we need a sort algorithm to sort the fields
@@ -74,53 +71,19 @@ func (n nullCompareble) Build() ([]string, []interface{}) {
 	return cond, nil
 }
 
-type nilComparable byte
-
-func (n nilComparable) Build() ([]string, []interface{}) {
-	return nil, nil
-}
-
 // Like means like
 type Like map[string]interface{}
 
 // Build implements the Comparable interface
 func (l Like) Build() ([]string, []interface{}) {
-	if nil == l || 0 == len(l) {
-		return nil, nil
-	}
-	var cond []string
-	var vals []interface{}
-	for k := range l {
-		cond = append(cond, k)
-	}
-	defaultSortAlgorithm(cond)
-	for j := 0; j < len(cond); j++ {
-		val := l[cond[j]]
-		cond[j] = cond[j] + " LIKE ?"
-		vals = append(vals, val)
-	}
-	return cond, vals
+	return build(l, " LIKE ")
 }
 
 type NotLike map[string]interface{}
 
 // Build implements the Comparable interface
 func (l NotLike) Build() ([]string, []interface{}) {
-	if nil == l || 0 == len(l) {
-		return nil, nil
-	}
-	var cond []string
-	var vals []interface{}
-	for k := range l {
-		cond = append(cond, k)
-	}
-	defaultSortAlgorithm(cond)
-	for j := 0; j < len(cond); j++ {
-		val := l[cond[j]]
-		cond[j] = cond[j] + " NOT LIKE ?"
-		vals = append(vals, val)
-	}
-	return cond, vals
+	return build(l, " NOT LIKE ")
 }
 
 //Eq means equal(=)
@@ -193,9 +156,23 @@ func (i In) Build() ([]string, []interface{}) {
 	return cond, vals
 }
 
+// expressionIn is IN whose values are expressions such as a Subquery
+type expressionIn struct {
+	m   map[string]interface{}
+	not bool
+}
+
+func (ei expressionIn) Build() ([]string, []interface{}) {
+	operator := " IN "
+	if ei.not {
+		operator = " NOT IN "
+	}
+	return build(ei.m, operator)
+}
+
 func buildIn(field string, vals []interface{}) (cond string) {
 	cond = strings.TrimRight(strings.Repeat("?,", len(vals)), ",")
-	cond = fmt.Sprintf("%s IN (%s)", quoteField(field), cond)
+	cond = fmt.Sprintf("%s IN (%s)", field, cond)
 	return
 }
 
@@ -223,7 +200,7 @@ func (i NotIn) Build() ([]string, []interface{}) {
 
 func buildNotIn(field string, vals []interface{}) (cond string) {
 	cond = strings.TrimRight(strings.Repeat("?,", len(vals)), ",")
-	cond = fmt.Sprintf("%s NOT IN (%s)", quoteField(field), cond)
+	cond = fmt.Sprintf("%s NOT IN (%s)", field, cond)
 	return
 }
 
@@ -280,6 +257,9 @@ func (nw NestWhere) Build() ([]string, []interface{}) {
 	var cond []string
 	var vals []interface{}
 	nestWhereString, nestWhereVals := whereConnector("AND", nw...)
+	if "" == nestWhereString {
+		return nil, nil
+	}
 	cond = append(cond, nestWhereString)
 	vals = nestWhereVals
 	return cond, vals
@@ -291,6 +271,9 @@ func (ow OrWhere) Build() ([]string, []interface{}) {
 	var cond []string
 	var vals []interface{}
 	orWhereString, orWhereVals := whereConnector("OR", ow...)
+	if "" == orWhereString {
+		return nil, nil
+	}
 	cond = append(cond, orWhereString)
 	vals = orWhereVals
 	return cond, vals
@@ -302,7 +285,7 @@ func build(m map[string]interface{}, op string) ([]string, []interface{}) {
 	}
 	length := len(m)
 	cond := make([]string, length)
-	vals := make([]interface{}, length)
+	vals := make([]interface{}, 0, length)
 	var i int
 	for key := range m {
 		cond[i] = key
@@ -310,14 +293,21 @@ func build(m map[string]interface{}, op string) ([]string, []interface{}) {
 	}
 	defaultSortAlgorithm(cond)
 	for i = 0; i < length; i++ {
-		vals[i] = m[cond[i]]
+		val := m[cond[i]]
+		if e, ok := val.(expression); ok {
+			exprString, exprVals := e.expression()
+			cond[i] = cond[i] + op + exprString
+			vals = append(vals, exprVals...)
+			continue
+		}
 		cond[i] = assembleExpression(cond[i], op)
+		vals = append(vals, val)
 	}
 	return cond, vals
 }
 
 func assembleExpression(field, op string) string {
-	return quoteField(field) + op + "?"
+	return field + op + "?"
 }
 
 func resolveKV(m map[string]interface{}) (keys []string, vals []interface{}) {
@@ -334,7 +324,7 @@ func resolveKV(m map[string]interface{}) (keys []string, vals []interface{}) {
 func resolveFields(m map[string]interface{}) []string {
 	var fields []string
 	for k := range m {
-		fields = append(fields, quoteField(k))
+		fields = append(fields, k)
 	}
 	defaultSortAlgorithm(fields)
 	return fields
@@ -361,11 +351,6 @@ func whereConnector(andOr string, conditions ...Comparable) (string, []interface
 	return whereString, values
 }
 
-// deprecated
-func quoteField(field string) string {
-	return field
-}
-
 type insertType string
 
 const (
@@ -374,118 +359,344 @@ const (
 	replaceInsert insertType = "REPLACE INTO"
 )
 
-func buildInsert(table string, setMap []map[string]interface{}, insertType insertType) (string, []interface{}, error) {
-	format := "%s %s (%s) VALUES %s"
-	var fields []string
+func (b *Builder) insertVerb(insertType insertType) (verb, suffix string, err error) {
+	switch insertType {
+	case ignoreInsert:
+		return b.dialect.InsertIgnore()
+	case replaceInsert:
+		verb, err = b.dialect.Replace()
+		return
+	}
+	return string(insertType), "", nil
+}
+
+func (b *Builder) buildInsert(table string, setMap []map[string]interface{}, insertType insertType) (string, []interface{}, error) {
+	format := "%s %s (%s) VALUES %s%s"
+	verb, suffix, err := b.insertVerb(insertType)
+	if nil != err {
+		return "", nil, err
+	}
+	rows, err := b.resolveInsert(table, setMap)
+	if nil != err {
+		return "", nil, err
+	}
 	var vals []interface{}
+	for _, rowVals := range rows.vals {
+		vals = append(vals, rowVals...)
+	}
+	return fmt.Sprintf(format, verb, rows.table, strings.Join(rows.fields, ","), strings.Join(rows.rows, ","), suffix), vals, nil
+}
+
+// insertRows is the quoted table and fields of an insert,
+// and the placeholders and values of each row
+type insertRows struct {
+	table  string
+	fields []string
+	rows   []string
+	vals   [][]interface{}
+}
+
+func (b *Builder) resolveInsert(table string, setMap []map[string]interface{}) (insertRows, error) {
 	if len(setMap) < 1 {
-		return "", nil, errInsertNullData
+		return insertRows{}, errInsertNullData
+	}
+	table, err := b.quoteIdent(table)
+	if nil != err {
+		return insertRows{}, err
+	}
+	fields := resolveFields(setMap[0])
+	quotedFields := make([]string, len(fields))
+	for i, field := range fields {
+		if quotedFields[i], err = b.quoteIdent(field); nil != err {
+			return insertRows{}, err
+		}
 	}
-	fields = resolveFields(setMap[0])
 	placeholder := "(" + strings.TrimRight(strings.Repeat("?,", len(fields)), ",") + ")"
-	var sets []string
+	rows := insertRows{
+		table:  table,
+		fields: quotedFields,
+		rows:   make([]string, 0, len(setMap)),
+		vals:   make([][]interface{}, 0, len(setMap)),
+	}
 	for _, mapItem := range setMap {
-		sets = append(sets, placeholder)
-		for _, field := range fields {
+		rowPlaceholder := placeholder
+		var exprs []string
+		rowVals := make([]interface{}, 0, len(fields))
+		for i, field := range fields {
 			val, ok := mapItem[field]
 			if !ok {
-				return "", nil, errInsertDataNotMatch
+				return insertRows{}, errInsertDataNotMatch
 			}
-			vals = append(vals, val)
+			if e, ok := val.(expression); ok {
+				if nil == exprs {
+					exprs = strings.Split(strings.Repeat("?", len(fields)), "")
+				}
+				exprString, exprVals := e.expression()
+				exprs[i] = exprString
+				rowVals = append(rowVals, exprVals...)
+				continue
+			}
+			rowVals = append(rowVals, val)
+		}
+		if nil != exprs {
+			rowPlaceholder = "(" + strings.Join(exprs, ",") + ")"
 		}
+		rows.rows = append(rows.rows, rowPlaceholder)
+		rows.vals = append(rows.vals, rowVals)
 	}
-	return fmt.Sprintf(format, insertType, quoteField(table), strings.Join(fields, ","), strings.Join(sets, ",")), vals, nil
+	return rows, nil
 }
 
-func buildInsertOnDuplicate(table string, data []map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
-	insertCond, insertVals, err := buildInsert(table, data, commonInsert)
+func (b *Builder) buildInsertOnDuplicate(table string, data []map[string]interface{}, update map[string]interface{}) (string, []interface{}, error) {
+	insertCond, insertVals, err := b.buildInsert(table, data, commonInsert)
+	if err != nil {
+		return "", nil, err
+	}
+	sets, updateVals, err := b.resolveUpdate(update)
+	if err != nil {
+		return "", nil, err
+	}
+	clause, err := b.dialect.Upsert(nil, sets)
 	if err != nil {
 		return "", nil, err
 	}
-	sets, updateVals := resolveUpdate(update)
-	format := "%s ON DUPLICATE KEY UPDATE %s"
-	cond := fmt.Sprintf(format, insertCond, sets)
 	vals := append(insertVals, updateVals...)
-	return cond, vals, nil
+	return insertCond + clause, vals, nil
 }
 
-func resolveUpdate(update map[string]interface{}) (string, []interface{}) {
+func (b *Builder) resolveUpdate(update map[string]interface{}) (string, []interface{}, error) {
 	keys, vals := resolveKV(update)
 	var sets string
-	for _, k := range keys {
-		sets += fmt.Sprintf("%s=?,", quoteField(k))
+	var setVals []interface{}
+	for i, k := range keys {
+		field, err := b.quoteIdent(k)
+		if nil != err {
+			return "", nil, err
+		}
+		if e, ok := vals[i].(expression); ok {
+			exprString, exprVals := e.expression()
+			sets += fmt.Sprintf("%s=%s,", field, exprString)
+			setVals = append(setVals, exprVals...)
+			continue
+		}
+		sets += fmt.Sprintf("%s=?,", field)
+		setVals = append(setVals, vals[i])
 	}
 	sets = strings.TrimRight(sets, ",")
-	return sets, vals
+	return sets, setVals, nil
 }
 
-func buildUpdate(table string, update map[string]interface{}, limit uint, conditions ...Comparable) (string, []interface{}, error) {
+func (b *Builder) buildUpdate(table string, update map[string]interface{}, limit uint, conditions ...Comparable) (string, []interface{}, error) {
 	format := "UPDATE %s SET %s"
-	sets, vals := resolveUpdate(update)
-	cond := fmt.Sprintf(format, quoteField(table), sets)
+	table, err := b.quoteIdent(table)
+	if nil != err {
+		return "", nil, err
+	}
+	sets, vals, err := b.resolveUpdate(update)
+	if nil != err {
+		return "", nil, err
+	}
+	cond := fmt.Sprintf(format, table, sets)
 	whereString, whereVals := whereConnector("AND", conditions...)
 	if "" != whereString {
 		cond = fmt.Sprintf("%s WHERE %s", cond, whereString)
 		vals = append(vals, whereVals...)
 	}
 	if limit > 0 {
-		cond += " LIMIT ?"
-		vals = append(vals, int(limit))
+		limitString, limitVals, err := b.dialect.UpdateLimit(limit)
+		if nil != err {
+			return "", nil, err
+		}
+		cond += limitString
+		vals = append(vals, limitVals...)
 	}
 	return cond, vals, nil
 }
 
-func buildDelete(table string, conditions ...Comparable) (string, []interface{}, error) {
+func (b *Builder) buildDelete(table string, conditions ...Comparable) (string, []interface{}, error) {
+	table, err := b.quoteIdent(table)
+	if nil != err {
+		return "", nil, err
+	}
 	whereString, vals := whereConnector("AND", conditions...)
 	if "" == whereString {
 		return fmt.Sprintf("DELETE FROM %s", table), nil, nil
 	}
 	format := "DELETE FROM %s WHERE %s"
 
-	cond := fmt.Sprintf(format, quoteField(table), whereString)
+	cond := fmt.Sprintf(format, table, whereString)
 	return cond, vals, nil
 }
 
-func splitCondition(conditions []Comparable) ([]Comparable, []Comparable) {
-	var having []Comparable
-	var i int
-	for i = len(conditions) - 1; i >= 0; i-- {
-		if _, ok := conditions[i].(nilComparable); ok {
-			break
-		}
+// JoinType is the type of a JOIN clause
+type JoinType string
+
+const (
+	// InnerJoin is INNER JOIN
+	InnerJoin JoinType = "INNER JOIN"
+	// LeftJoin is LEFT JOIN
+	LeftJoin JoinType = "LEFT JOIN"
+	// RightJoin is RIGHT JOIN
+	RightJoin JoinType = "RIGHT JOIN"
+)
+
+// Join describes a JOIN clause used as the value of "_join".
+// On supports the same keys as where, use Col to compare with another column.
+type Join struct {
+	Type  JoinType
+	Table string
+	On    map[string]interface{}
+}
+
+// Col is a column name used as a value in where,
+// it's put into the statement instead of a placeholder
+type Col string
+
+func (c Col) expression() (string, []interface{}) {
+	return string(c), nil
+}
+
+// expression is a value which is put into the statement as it is
+// instead of a placeholder
+type expression interface {
+	expression() (string, []interface{})
+}
+
+// Raw is a piece of SQL with its own arguments, it's put into the statement
+// as it is instead of a placeholder. It could be used as the value of where,
+// update and insert maps.
+type Raw struct {
+	sql  string
+	args []interface{}
+}
+
+// Expr returns a Raw, args are bound to the ? placeholders in sql:
+//
+//	Expr("counter+?", 1)
+//	Expr("NOW()")
+//	Expr("VALUES(total)")
+func Expr(sql string, args ...interface{}) Raw {
+	return Raw{sql: sql, args: args}
+}
+
+func (r Raw) expression() (string, []interface{}) {
+	return r.sql, r.args
+}
+
+type joinClause struct {
+	typ   JoinType
+	table string
+	on    []Comparable
+}
+
+type selectStmt struct {
+	with []CTE
+	// table is the alias of from if from isn't nil
+	table  string
+	from   *Subquery
+	joins  []joinClause
+	fields []string
+	// exprFields are put after fields
+	exprFields []Raw
+	where      []Comparable
+	groupBy    string
+	having     []Comparable
+	orderBy    string
+	limit      *eleLimit
+	lockMode   string
+}
+
+func (b *Builder) resolveJoin(join Join) (joinClause, error) {
+	conditions, err := b.getWhereConditions(join.On, defaultIgnoreKeys)
+	if nil != err {
+		return joinClause{}, err
 	}
-	if i >= 0 && i < len(conditions)-1 {
-		having = conditions[i+1:]
-		return conditions[:i], having
+	return joinClause{typ: join.Type, table: join.Table, on: conditions}, nil
+}
+
+func (b *Builder) buildJoin(join joinClause) (string, []interface{}, error) {
+	switch join.typ {
+	case InnerJoin, LeftJoin, RightJoin:
+	default:
+		return "", nil, errJoinType
+	}
+	table, err := b.quoteIdent(join.table)
+	if nil != err {
+		return "", nil, err
 	}
-	return conditions, nil
+	on, vals := whereConnector("AND", join.on...)
+	if "" == on {
+		return "", nil, errJoinOnEmpty
+	}
+	return fmt.Sprintf(" %s %s ON %s", join.typ, table, on), vals, nil
 }
 
-func buildSelect(table string, ufields []string, groupBy, orderBy, lockMode string, limit *eleLimit, conditions ...Comparable) (string, []interface{}, error) {
+func (b *Builder) buildSelect(stmt selectStmt) (string, []interface{}, error) {
+	var vals []interface{}
 	fields := "*"
-	if len(ufields) > 0 {
-		for i := range ufields {
-			ufields[i] = quoteField(ufields[i])
+	if len(stmt.fields)+len(stmt.exprFields) > 0 {
+		quotedFields := make([]string, len(stmt.fields), len(stmt.fields)+len(stmt.exprFields))
+		for i := range stmt.fields {
+			quoted, err := b.quoteIdent(stmt.fields[i])
+			if nil != err {
+				return "", nil, err
+			}
+			quotedFields[i] = quoted
+		}
+		for _, expr := range stmt.exprFields {
+			quotedFields = append(quotedFields, expr.sql)
+			vals = append(vals, expr.args...)
 		}
-		fields = strings.Join(ufields, ",")
+		fields = strings.Join(quotedFields, ",")
 	}
+	table, err := b.quoteIdent(stmt.table)
+	if nil != err {
+		return "", nil, err
+	}
+	groupBy, err := b.quoteList(stmt.groupBy, false)
+	if nil != err {
+		return "", nil, err
+	}
+	orderBy, err := b.quoteList(stmt.orderBy, true)
+	if nil != err {
+		return "", nil, err
+	}
+	withString, withVals, err := b.buildWith(stmt.with)
+	if nil != err {
+		return "", nil, err
+	}
+	vals = append(withVals, vals...)
 	bd := strings.Builder{}
+	bd.WriteString(withString)
 	bd.WriteString("SELECT ")
 	bd.WriteString(fields)
 	bd.WriteString(" FROM ")
+	if nil != stmt.from {
+		fromString, fromVals := stmt.from.expression()
+		bd.WriteString(fromString)
+		bd.WriteString(" AS ")
+		vals = append(vals, fromVals...)
+	}
 	bd.WriteString(table)
-	where, having := splitCondition(conditions)
-	whereString, vals := whereConnector("AND", where...)
+	for _, join := range stmt.joins {
+		joinString, joinVals, err := b.buildJoin(join)
+		if nil != err {
+			return "", nil, err
+		}
+		bd.WriteString(joinString)
+		vals = append(vals, joinVals...)
+	}
+	whereString, whereVals := whereConnector("AND", stmt.where...)
 	if "" != whereString {
 		bd.WriteString(" WHERE ")
 		bd.WriteString(whereString)
+		vals = append(vals, whereVals...)
 	}
 	if "" != groupBy {
 		bd.WriteString(" GROUP BY ")
 		bd.WriteString(groupBy)
 	}
-	if nil != having {
-		havingString, havingVals := whereConnector("AND", having...)
+	if havingString, havingVals := whereConnector("AND", stmt.having...); "" != havingString {
 		bd.WriteString(" HAVING ")
 		bd.WriteString(havingString)
 		vals = append(vals, havingVals...)
@@ -494,12 +705,17 @@ func buildSelect(table string, ufields []string, groupBy, orderBy, lockMode stri
 		bd.WriteString(" ORDER BY ")
 		bd.WriteString(orderBy)
 	}
-	if nil != limit {
-		bd.WriteString(" LIMIT ?,?")
-		vals = append(vals, int(limit.begin), int(limit.step))
+	if nil != stmt.limit {
+		limitString, limitVals := b.dialect.Limit(stmt.limit.begin, stmt.limit.step)
+		bd.WriteString(limitString)
+		vals = append(vals, limitVals...)
 	}
-	if "" != lockMode {
-		bd.WriteString(allowedLockMode[lockMode])
+	if "" != stmt.lockMode {
+		lockString, err := b.dialect.Lock(stmt.lockMode)
+		if nil != err {
+			return "", nil, err
+		}
+		bd.WriteString(lockString)
 	}
 	return bd.String(), vals, nil
 }
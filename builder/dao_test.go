@@ -250,7 +250,7 @@ func TestBuildInsert(t *testing.T) {
 	}
 	ass := assert.New(t)
 	for _, tc := range data {
-		actualStr, actualVals, err := buildInsert(tc.table, tc.data, tc.insertType)
+		actualStr, actualVals, err := defaultBuilder.buildInsert(tc.table, tc.data, tc.insertType)
 		ass.Equal(tc.outErr, err)
 		ass.Equal(tc.outStr, actualStr)
 		ass.Equal(tc.outVals, actualVals)
@@ -291,7 +291,7 @@ func TestBuildInsertOnDuplicate(t *testing.T) {
 	}
 	ass := assert.New(t)
 	for _, tc := range data {
-		cond, vals, err := buildInsertOnDuplicate(tc.table, tc.data, tc.update)
+		cond, vals, err := defaultBuilder.buildInsertOnDuplicate(tc.table, tc.data, tc.update)
 		ass.Equal(tc.outErr, err)
 		ass.Equal(tc.outStr, cond)
 		ass.Equal(tc.outVals, vals)
@@ -326,7 +326,7 @@ func TestBuildUpdate(t *testing.T) {
 	}
 	ass := assert.New(t)
 	for _, tc := range data {
-		cond, vals, err := buildUpdate(tc.table, tc.data, 0, tc.conditions...)
+		cond, vals, err := defaultBuilder.buildUpdate(tc.table, tc.data, 0, tc.conditions...)
 		ass.Equal(tc.outErr, err)
 		ass.Equal(tc.outStr, cond)
 		ass.Equal(tc.outVals, vals)
@@ -357,7 +357,7 @@ func TestBuildDelete(t *testing.T) {
 	}
 	ass := assert.New(t)
 	for _, tc := range data {
-		actualStr, actualVals, err := buildDelete(tc.table, tc.where...)
+		actualStr, actualVals, err := defaultBuilder.buildDelete(tc.table, tc.where...)
 		ass.Equal(tc.outErr, err)
 		ass.Equal(tc.outStr, actualStr)
 		ass.Equal(tc.outVals, actualVals)
@@ -421,7 +421,15 @@ func TestBuildSelect(t *testing.T) {
 	}
 	ass := assert.New(t)
 	for _, tc := range data {
-		cond, vals, err := buildSelect(tc.table, tc.fields, tc.groupBy, tc.orderBy, tc.lockMode, tc.limit, tc.conditions...)
+		cond, vals, err := defaultBuilder.buildSelect(selectStmt{
+			table:    tc.table,
+			fields:   tc.fields,
+			where:    tc.conditions,
+			groupBy:  tc.groupBy,
+			orderBy:  tc.orderBy,
+			limit:    tc.limit,
+			lockMode: tc.lockMode,
+		})
 		ass.Equal(tc.outErr, err)
 		ass.Equal(tc.outStr, cond)
 		ass.Equal(tc.outVals, vals)
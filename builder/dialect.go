@@ -0,0 +1,284 @@
+package builder
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedSyntax reports the dialect doesn't support the requested syntax
+var ErrUnsupportedSyntax = errors.New("[builder] unsupported syntax")
+
+// Dialect describes the parts of SQL syntax that differ between databases.
+// The package-level Build* functions use MySQL, use New to build statements
+// for other databases. Implement it to teach builder a new database.
+type Dialect interface {
+	// Name is the name of the database, used in error messages
+	Name() string
+	// Placeholder returns the placeholder of the n-th(starting from 1) argument
+	Placeholder(n int) string
+	// QuoteIdent quotes a single identifier such as a table or a column name
+	QuoteIdent(ident string) string
+	// QuoteString returns the string literal of s, it's used by Interpolate
+	QuoteString(s string) string
+	// QuoteBytes returns the binary literal of b, it's used by Interpolate
+	QuoteBytes(b []byte) string
+	// Limit returns the LIMIT clause of a SELECT statement and its arguments
+	Limit(offset, count uint) (string, []interface{})
+	// UpdateLimit returns the LIMIT clause of an UPDATE statement and its arguments
+	UpdateLimit(count uint) (string, []interface{}, error)
+	// Lock returns the locking clause of the value of "_lockMode"
+	Lock(mode string) (string, error)
+	// InsertIgnore returns the leading keywords of an insert which skips
+	// the conflicting rows and the clause appended to its VALUES list
+	InsertIgnore() (verb, suffix string, err error)
+	// Replace returns the leading keywords of an insert which replaces
+	// the conflicting rows
+	Replace() (string, error)
+	// Upsert returns the clause appended to INSERT ... VALUES ... which updates
+	// the conflicting rows with sets, conflict is the list of conflict target columns.
+	// sets is empty if the conflicting rows should be left as they are.
+	Upsert(conflict []string, sets string) (string, error)
+	// Inserted refers to a column of the row proposed for insertion
+	// in the clause of Upsert
+	Inserted(column string) string
+	// Compound returns how a SELECT statement is wrapped as an operand of
+	// the set operator op, a format such as (%s)
+	Compound(op SetOperator) (string, error)
+}
+
+var (
+	// MySQL uses ? placeholders, `quoted` identifiers and LIMIT offset,count
+	MySQL Dialect = mysqlDialect{}
+	// PostgreSQL uses $n placeholders, "quoted" identifiers and LIMIT count OFFSET offset
+	PostgreSQL Dialect = postgresDialect{}
+	// SQLite uses ? placeholders, "quoted" identifiers and LIMIT count OFFSET offset
+	SQLite Dialect = sqliteDialect{}
+)
+
+func unsupported(d Dialect, syntax string) error {
+	return fmt.Errorf("%w: %s doesn't support %s", ErrUnsupportedSyntax, d.Name(), syntax)
+}
+
+func quoteWith(ident string, q string) string {
+	return q + strings.Replace(ident, q, q+q, -1) + q
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Placeholder(n int) string { return paramPlaceHolder }
+
+func (mysqlDialect) QuoteIdent(ident string) string { return quoteWith(ident, "`") }
+
+var mysqlStringReplacer = strings.NewReplacer(
+	`\`, `\\`, `'`, `\'`, `"`, `\"`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`,
+)
+
+// QuoteString of MySQL escapes by backslashes, which is the default unless NO_BACKSLASH_ESCAPES is set
+func (mysqlDialect) QuoteString(s string) string {
+	return "'" + mysqlStringReplacer.Replace(s) + "'"
+}
+
+func (mysqlDialect) QuoteBytes(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" }
+
+func (mysqlDialect) Limit(offset, count uint) (string, []interface{}) {
+	return " LIMIT ?,?", []interface{}{int(offset), int(count)}
+}
+
+func (mysqlDialect) UpdateLimit(count uint) (string, []interface{}, error) {
+	return " LIMIT ?", []interface{}{int(count)}, nil
+}
+
+var allowedLockMode = map[string]string{
+	"share":     " LOCK IN SHARE MODE",
+	"exclusive": " FOR UPDATE",
+}
+
+func (mysqlDialect) Lock(mode string) (string, error) {
+	clause, ok := allowedLockMode[mode]
+	if !ok {
+		return "", errNotAllowedLockMode
+	}
+	return clause, nil
+}
+
+func (mysqlDialect) InsertIgnore() (string, string, error) {
+	return string(ignoreInsert), "", nil
+}
+
+func (mysqlDialect) Replace() (string, error) {
+	return string(replaceInsert), nil
+}
+
+func (d mysqlDialect) Upsert(conflict []string, sets string) (string, error) {
+	if "" == sets {
+		if len(conflict) == 0 {
+			return "", unsupported(d, "ON DUPLICATE KEY UPDATE without any column")
+		}
+		sets = conflict[0] + "=" + conflict[0]
+	}
+	return " ON DUPLICATE KEY UPDATE " + sets, nil
+}
+
+func (mysqlDialect) Inserted(column string) string {
+	return "VALUES(" + column + ")"
+}
+
+// Compound of MySQL requires 8.0.31 or later for INTERSECT and EXCEPT
+func (mysqlDialect) Compound(op SetOperator) (string, error) {
+	return "(%s)", nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgresql" }
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+func (postgresDialect) QuoteIdent(ident string) string { return quoteWith(ident, `"`) }
+
+func (postgresDialect) QuoteString(s string) string { return quoteWith(s, "'") }
+
+func (postgresDialect) QuoteBytes(b []byte) string { return `'\x` + hex.EncodeToString(b) + "'" }
+
+func (postgresDialect) Limit(offset, count uint) (string, []interface{}) {
+	return " LIMIT ? OFFSET ?", []interface{}{int(count), int(offset)}
+}
+
+func (d postgresDialect) UpdateLimit(count uint) (string, []interface{}, error) {
+	return "", nil, unsupported(d, "UPDATE ... LIMIT")
+}
+
+func (postgresDialect) Lock(mode string) (string, error) {
+	switch mode {
+	case "share":
+		return " FOR SHARE", nil
+	case "exclusive":
+		return " FOR UPDATE", nil
+	}
+	return "", errNotAllowedLockMode
+}
+
+func (postgresDialect) InsertIgnore() (string, string, error) {
+	return string(commonInsert), " ON CONFLICT DO NOTHING", nil
+}
+
+func (d postgresDialect) Replace() (string, error) {
+	return "", unsupported(d, "REPLACE INTO")
+}
+
+func (d postgresDialect) Upsert(conflict []string, sets string) (string, error) {
+	if "" == sets {
+		return onConflictDoNothing(conflict), nil
+	}
+	if len(conflict) == 0 {
+		return "", unsupported(d, "ON CONFLICT DO UPDATE without conflict target")
+	}
+	return " ON CONFLICT (" + strings.Join(conflict, ",") + ") DO UPDATE SET " + sets, nil
+}
+
+func (postgresDialect) Inserted(column string) string {
+	return "EXCLUDED." + column
+}
+
+func (postgresDialect) Compound(op SetOperator) (string, error) {
+	return "(%s)", nil
+}
+
+func onConflictDoNothing(conflict []string) string {
+	if len(conflict) == 0 {
+		return " ON CONFLICT DO NOTHING"
+	}
+	return " ON CONFLICT (" + strings.Join(conflict, ",") + ") DO NOTHING"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Placeholder(n int) string { return paramPlaceHolder }
+
+func (sqliteDialect) QuoteIdent(ident string) string { return quoteWith(ident, `"`) }
+
+func (sqliteDialect) QuoteString(s string) string { return quoteWith(s, "'") }
+
+func (sqliteDialect) QuoteBytes(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" }
+
+func (sqliteDialect) Limit(offset, count uint) (string, []interface{}) {
+	return " LIMIT ? OFFSET ?", []interface{}{int(count), int(offset)}
+}
+
+func (d sqliteDialect) UpdateLimit(count uint) (string, []interface{}, error) {
+	return "", nil, unsupported(d, "UPDATE ... LIMIT")
+}
+
+func (d sqliteDialect) Lock(mode string) (string, error) {
+	switch mode {
+	case "share", "exclusive":
+		return "", unsupported(d, "locking reads")
+	}
+	return "", errNotAllowedLockMode
+}
+
+func (sqliteDialect) InsertIgnore() (string, string, error) {
+	return "INSERT OR IGNORE INTO", "", nil
+}
+
+func (sqliteDialect) Replace() (string, error) {
+	return string(replaceInsert), nil
+}
+
+func (sqliteDialect) Upsert(conflict []string, sets string) (string, error) {
+	if "" == sets {
+		return onConflictDoNothing(conflict), nil
+	}
+	if len(conflict) == 0 {
+		return " ON CONFLICT DO UPDATE SET " + sets, nil
+	}
+	return " ON CONFLICT (" + strings.Join(conflict, ",") + ") DO UPDATE SET " + sets, nil
+}
+
+func (sqliteDialect) Inserted(column string) string {
+	return "excluded." + column
+}
+
+// Compound of SQLite doesn't allow parenthesized operands, so they're wrapped as derived tables
+func (d sqliteDialect) Compound(op SetOperator) (string, error) {
+	if IntersectAll == op || ExceptAll == op {
+		return "", unsupported(d, string(op))
+	}
+	return "SELECT * FROM (%s)", nil
+}
+
+// rebind replaces the ? placeholders in sql with the ones of the dialect.
+// Question marks inside quoted strings and identifiers are left untouched.
+func rebind(d Dialect, sql string) string {
+	if d.Placeholder(1) == paramPlaceHolder {
+		return sql
+	}
+	var bd strings.Builder
+	bd.Grow(len(sql) + 8)
+	var quote byte
+	n := 0
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			bd.WriteString(d.Placeholder(n))
+			continue
+		}
+		bd.WriteByte(c)
+	}
+	return bd.String()
+}
@@ -0,0 +1,167 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectSelect(t *testing.T) {
+	where := map[string]interface{}{
+		"name":      "deen",
+		"age >":     20,
+		"city in":   []string{"Beijing", "Shanghai"},
+		"_orderby":  "age desc",
+		"_limit":    []uint{10, 20},
+		"_lockMode": "share",
+	}
+	var data = []struct {
+		dialect Dialect
+		cond    string
+		vals    []interface{}
+		err     error
+	}{
+		{
+			dialect: MySQL,
+			cond:    "SELECT id,name FROM tb WHERE (name=? AND city IN (?,?) AND age>?) ORDER BY age desc LIMIT ?,? LOCK IN SHARE MODE",
+			vals:    []interface{}{"deen", "Beijing", "Shanghai", 20, 10, 20},
+		},
+		{
+			dialect: PostgreSQL,
+			cond:    "SELECT id,name FROM tb WHERE (name=$1 AND city IN ($2,$3) AND age>$4) ORDER BY age desc LIMIT $5 OFFSET $6 FOR SHARE",
+			vals:    []interface{}{"deen", "Beijing", "Shanghai", 20, 20, 10},
+		},
+		{
+			dialect: SQLite,
+			err:     ErrUnsupportedSyntax,
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		cond, vals, err := New(tc.dialect).BuildSelect("tb", where, []string{"id", "name"})
+		if tc.err != nil {
+			ass.True(errors.Is(err, tc.err), "dialect:%s", tc.dialect.Name())
+			continue
+		}
+		ass.NoError(err)
+		ass.Equal(tc.cond, cond)
+		ass.Equal(tc.vals, vals)
+	}
+	delete(where, "_lockMode")
+	cond, vals, err := New(SQLite).BuildSelect("tb", where, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM tb WHERE (name=? AND city IN (?,?) AND age>?) ORDER BY age desc LIMIT ? OFFSET ?", cond)
+	ass.Equal([]interface{}{"deen", "Beijing", "Shanghai", 20, 20, 10}, vals)
+}
+
+func TestDialectInsert(t *testing.T) {
+	data := []map[string]interface{}{
+		{"a": 1, "b": 2},
+		{"a": 3, "b": 4},
+	}
+	update := map[string]interface{}{"b": 5}
+	var testCase = []struct {
+		dialect   Dialect
+		ignore    string
+		replace   string
+		duplicate string
+	}{
+		{
+			dialect:   MySQL,
+			ignore:    "INSERT IGNORE INTO tb (a,b) VALUES (?,?),(?,?)",
+			replace:   "REPLACE INTO tb (a,b) VALUES (?,?),(?,?)",
+			duplicate: "INSERT INTO tb (a,b) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE b=?",
+		},
+		{
+			dialect: PostgreSQL,
+			ignore:  "INSERT INTO tb (a,b) VALUES ($1,$2),($3,$4) ON CONFLICT DO NOTHING",
+		},
+		{
+			dialect:   SQLite,
+			ignore:    "INSERT OR IGNORE INTO tb (a,b) VALUES (?,?),(?,?)",
+			replace:   "REPLACE INTO tb (a,b) VALUES (?,?),(?,?)",
+			duplicate: "INSERT INTO tb (a,b) VALUES (?,?),(?,?) ON CONFLICT DO UPDATE SET b=?",
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range testCase {
+		b := New(tc.dialect)
+		cond, vals, err := b.BuildInsertIgnore("tb", data)
+		ass.NoError(err)
+		ass.Equal(tc.ignore, cond)
+		ass.Equal([]interface{}{1, 2, 3, 4}, vals)
+
+		cond, _, err = b.BuildReplaceInsert("tb", data)
+		if "" == tc.replace {
+			ass.True(errors.Is(err, ErrUnsupportedSyntax), "dialect:%s", tc.dialect.Name())
+		} else {
+			ass.NoError(err)
+			ass.Equal(tc.replace, cond)
+		}
+
+		cond, vals, err = b.BuildInsertOnDuplicate("tb", data, update)
+		if "" == tc.duplicate {
+			ass.True(errors.Is(err, ErrUnsupportedSyntax), "dialect:%s", tc.dialect.Name())
+		} else {
+			ass.NoError(err)
+			ass.Equal(tc.duplicate, cond)
+			ass.Equal([]interface{}{1, 2, 3, 4, 5}, vals)
+		}
+	}
+}
+
+func TestDialectUpdate(t *testing.T) {
+	ass := assert.New(t)
+	where := map[string]interface{}{"id": 1}
+	update := map[string]interface{}{"name": "deen"}
+	cond, vals, err := New(PostgreSQL).BuildUpdate("tb", where, update)
+	ass.NoError(err)
+	ass.Equal("UPDATE tb SET name=$1 WHERE (id=$2)", cond)
+	ass.Equal([]interface{}{"deen", 1}, vals)
+
+	where["_limit"] = 10
+	_, _, err = New(PostgreSQL).BuildUpdate("tb", where, update)
+	ass.True(errors.Is(err, ErrUnsupportedSyntax))
+	cond, vals, err = New(nil).BuildUpdate("tb", where, update)
+	ass.NoError(err)
+	ass.Equal("UPDATE tb SET name=? WHERE (id=?) LIMIT ?", cond)
+	ass.Equal([]interface{}{"deen", 1, 10}, vals)
+
+	cond, vals, err = New(PostgreSQL).BuildDelete("tb", map[string]interface{}{"id in": []int{1, 2}})
+	ass.NoError(err)
+	ass.Equal("DELETE FROM tb WHERE (id IN ($1,$2))", cond)
+	ass.Equal([]interface{}{1, 2}, vals)
+}
+
+func TestRebind(t *testing.T) {
+	var data = []struct {
+		in  string
+		out string
+	}{
+		{"SELECT * FROM tb", "SELECT * FROM tb"},
+		{"a=? AND b IN (?,?)", "a=$1 AND b IN ($2,$3)"},
+		{"a='?' AND b=?", "a='?' AND b=$1"},
+		{`"wh?t"=? AND c='it''s ?'`, `"wh?t"=$1 AND c='it''s ?'`},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		ass.Equal(tc.out, rebind(PostgreSQL, tc.in))
+		ass.Equal(tc.in, rebind(MySQL, tc.in))
+	}
+	cond, vals, err := New(PostgreSQL).NamedQuery("select * from tb where name={{name}} and age in {{age}}", map[string]interface{}{
+		"name": "deen",
+		"age":  []int{1, 2},
+	})
+	ass.NoError(err)
+	ass.Equal("select * from tb where name=$1 and age in ($2,$3)", cond)
+	ass.Equal([]interface{}{"deen", 1, 2}, vals)
+}
+
+func TestQuoteIdent(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal("`order`", MySQL.QuoteIdent("order"))
+	ass.Equal("`a``b`", MySQL.QuoteIdent("a`b"))
+	ass.Equal(`"order"`, PostgreSQL.QuoteIdent("order"))
+	ass.Equal(`"a""b"`, SQLite.QuoteIdent(`a"b`))
+}
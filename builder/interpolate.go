@@ -0,0 +1,379 @@
+package builder
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInterpolateArgs = errors.New("[builder] the number of args doesn't match the placeholders")
+
+const (
+	maskedValue           = "xxxxx"
+	interpolateTimeFormat = "2006-01-02 15:04:05.999999"
+)
+
+// the words between a column and its placeholders, such as age BETWEEN ? AND ?
+var interpolateKeywords = map[string]struct{}{
+	"AND":     struct{}{},
+	"NOT":     struct{}{},
+	"IN":      struct{}{},
+	"LIKE":    struct{}{},
+	"BETWEEN": struct{}{},
+	"IS":      struct{}{},
+}
+
+// Interpolate renders a MySQL statement with its args in place of the placeholders,
+// the values of masked columns are replaced by 'xxxxx', such as Interpolate(cond, vals, "password").
+// The result is for logging and debugging only, never execute it, use the args instead.
+//
+// Strings and []byte are quoted in the way of the dialect, time.Time is formatted as
+// 'YYYY-MM-DD hh:mm:ss[.ffffff]' in its location, nil and NullType are NULL, and a driver.Valuer
+// is rendered by its Value. The column of a placeholder is the identifier before it,
+// like name=?, age IN (?,?) and the column list of INSERT ... VALUES, or the column compared
+// with the expression it's in, like password=SHA2(?,256). A masked column matches both
+// a column and a column qualified by a table.
+func Interpolate(sql string, args []interface{}, masked ...string) (string, error) {
+	return defaultBuilder.Interpolate(sql, args, masked...)
+}
+
+// Interpolate is the same as the package-level Interpolate but in the dialect of b
+func (b *Builder) Interpolate(sql string, args []interface{}, masked ...string) (string, error) {
+	in := interpolator{
+		dialect: b.dialect,
+		args:    args,
+		masked:  make(map[string]struct{}, len(masked)),
+		// the dialect escapes backslashes in its strings if and only if they're escapes
+		backslash: b.dialect.QuoteString(`\`) != `'\'`,
+	}
+	for _, column := range masked {
+		in.masked[strings.ToLower(column)] = struct{}{}
+	}
+	if placeholder := b.dialect.Placeholder(1); paramPlaceHolder != placeholder {
+		in.prefix = strings.TrimSuffix(placeholder, "1")
+	}
+	return in.interpolate(sql)
+}
+
+type interpolator struct {
+	dialect Dialect
+	args    []interface{}
+	masked  map[string]struct{}
+	// prefix is the prefix of numbered placeholders such as $ of $1, empty for ?
+	prefix string
+	// backslash reports whether backslashes escape in the strings of the dialect
+	backslash bool
+
+	// column is the last identifier, which the following placeholders belong to
+	// if they aren't on the right of a comparison
+	column string
+	// comparisons are the comparisons pending at each depth, whose placeholders
+	// belong to the column on their left even inside functions like SHA2(?,256)
+	comparisons []comparison
+	// group is the identifiers in the last parentheses, the column list before VALUES
+	group, insertColumns []string
+	depth                int
+	// valuesDepth is the depth of VALUES, -1 outside of VALUES, and position is
+	// the index of the current value of the row
+	valuesDepth, position int
+}
+
+// comparison is the left column of a comparison operator and whether its right side
+// has got a value, the comparison ends at the next word after the value
+type comparison struct {
+	column string
+	valued bool
+}
+
+func (in *interpolator) interpolate(sql string) (string, error) {
+	var bd strings.Builder
+	bd.Grow(len(sql) + len(in.args)*8)
+	in.valuesDepth = -1
+	n := 0
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// backslashes escape in strings but not in identifiers
+			end := closingQuote(sql, i, in.backslash && c != '`')
+			bd.WriteString(sql[i:end])
+			if c == '\'' {
+				in.valued()
+			} else if end-i >= 2 {
+				q := string(c)
+				in.word(strings.Replace(sql[i+1:end-1], q+q, q, -1), i > 0 && sql[i-1] == '.')
+			}
+			i = end
+			continue
+		case "" == in.prefix && c == '?':
+			value, err := in.value(n)
+			if nil != err {
+				return "", err
+			}
+			bd.WriteString(value)
+			in.valued()
+			n++
+			i++
+			continue
+		case "" != in.prefix && strings.HasPrefix(sql[i:], in.prefix) && i+len(in.prefix) < len(sql) && isDigit(sql[i+len(in.prefix)]):
+			end := i + len(in.prefix)
+			for end < len(sql) && isDigit(sql[end]) {
+				end++
+			}
+			index, _ := strconv.Atoi(sql[i+len(in.prefix) : end])
+			value, err := in.value(index - 1)
+			if nil != err {
+				return "", err
+			}
+			bd.WriteString(value)
+			in.valued()
+			if index > n {
+				n = index
+			}
+			i = end
+			continue
+		case isWordByte(c):
+			end := i
+			for end < len(sql) && isWordByte(sql[end]) {
+				end++
+			}
+			in.word(sql[i:end], i > 0 && sql[i-1] == '.')
+			bd.WriteString(sql[i:end])
+			i = end
+			continue
+		case isOperatorByte(c):
+			end := i
+			for end < len(sql) && isOperatorByte(sql[end]) {
+				end++
+			}
+			in.compare()
+			bd.WriteString(sql[i:end])
+			i = end
+			continue
+		case c == '(':
+			if in.depth == in.valuesDepth {
+				in.position = 0
+			}
+			in.depth++
+			in.group = nil
+		case c == ')':
+			if in.depth < len(in.comparisons) {
+				in.comparisons = in.comparisons[:in.depth]
+			}
+			in.depth--
+			if nil != in.group {
+				in.insertColumns = in.group
+			}
+			in.valued()
+		case c == ',':
+			if in.depth == in.valuesDepth+1 {
+				in.position++
+			}
+			in.endComparison()
+		}
+		bd.WriteByte(c)
+		i++
+	}
+	if n != len(in.args) {
+		return "", errInterpolateArgs
+	}
+	return bd.String(), nil
+}
+
+// word handles an identifier or a keyword, qualified means it follows a dot like u.name
+func (in *interpolator) word(w string, qualified bool) {
+	upper := strings.ToUpper(w)
+	if "VALUES" == upper {
+		in.valuesDepth = in.depth
+		return
+	}
+	if in.depth == in.valuesDepth {
+		// the end of VALUES, such as ON DUPLICATE KEY UPDATE
+		in.valuesDepth = -1
+	}
+	_, keyword := interpolateKeywords[upper]
+	if cmp := in.comparison(); !qualified && nil != cmp {
+		// a word starts the right side of a comparison, like SHA2 of password=SHA2(?),
+		// or follows it, like AND and LIMIT
+		if cmp.valued {
+			in.endComparison()
+		} else if !keyword {
+			cmp.valued = true
+		}
+	}
+	if keyword {
+		return
+	}
+	if qualified {
+		in.column += "." + w
+		if len(in.group) > 0 {
+			in.group[len(in.group)-1] = in.column
+		}
+		return
+	}
+	in.column = w
+	in.group = append(in.group, w)
+}
+
+// compare starts a comparison of the last identifier at the current depth
+func (in *interpolator) compare() {
+	if in.depth < 0 {
+		return
+	}
+	for len(in.comparisons) <= in.depth {
+		in.comparisons = append(in.comparisons, comparison{})
+	}
+	in.comparisons[in.depth] = comparison{column: in.column}
+}
+
+// comparison returns the comparison pending at the current depth, nil if there isn't one
+func (in *interpolator) comparison() *comparison {
+	if in.depth < 0 || in.depth >= len(in.comparisons) || "" == in.comparisons[in.depth].column {
+		return nil
+	}
+	return &in.comparisons[in.depth]
+}
+
+// valued marks the right side of the comparison at the current depth has got a value
+func (in *interpolator) valued() {
+	if cmp := in.comparison(); nil != cmp {
+		cmp.valued = true
+	}
+}
+
+func (in *interpolator) endComparison() {
+	if cmp := in.comparison(); nil != cmp {
+		*cmp = comparison{}
+	}
+}
+
+// value renders the n-th(starting from 0) arg
+func (in *interpolator) value(n int) (string, error) {
+	if n < 0 || n >= len(in.args) {
+		return "", errInterpolateArgs
+	}
+	column := in.column
+	// the innermost pending comparison, such as the one of password=SHA2(?,256)
+	for depth := in.depth; depth >= 0; depth-- {
+		if depth < len(in.comparisons) && "" != in.comparisons[depth].column {
+			column = in.comparisons[depth].column
+			break
+		}
+	}
+	if in.valuesDepth >= 0 && in.depth > in.valuesDepth {
+		column = ""
+		if in.position < len(in.insertColumns) {
+			column = in.insertColumns[in.position]
+		}
+	}
+	if in.isMasked(column) {
+		return in.dialect.QuoteString(maskedValue), nil
+	}
+	return literal(in.dialect, in.args[n])
+}
+
+func (in *interpolator) isMasked(column string) bool {
+	if len(in.masked) == 0 || "" == column {
+		return false
+	}
+	column = strings.ToLower(column)
+	if _, ok := in.masked[column]; ok {
+		return true
+	}
+	if dot := strings.LastIndexByte(column, '.'); -1 != dot {
+		_, ok := in.masked[column[dot+1:]]
+		return ok
+	}
+	return false
+}
+
+// literal returns the SQL literal of v in the dialect d
+func literal(d Dialect, v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL", nil
+		}
+		value, err := valuer.Value()
+		if nil != err {
+			return "", err
+		}
+		v = value
+	}
+	switch val := v.(type) {
+	case nil, NullType:
+		return "NULL", nil
+	case time.Time:
+		return d.QuoteString(val.Format(interpolateTimeFormat)), nil
+	case []byte:
+		if nil == val {
+			return "NULL", nil
+		}
+		return d.QuoteBytes(val), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return literal(d, rv.Elem().Interface())
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return d.QuoteString(strconv.FormatFloat(f, 'g', -1, 64)), nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()), nil
+	case reflect.String:
+		return d.QuoteString(rv.String()), nil
+	}
+	return "", fmt.Errorf("[builder] can't interpolate the value of type %T", v)
+}
+
+// closingQuote returns the index after the quote starting at sql[start],
+// a doubled quote inside is an escaped one, so is a quote after a backslash
+// if backslash is true
+func closingQuote(sql string, start int, backslash bool) int {
+	q := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		if backslash && sql[i] == '\\' {
+			i++
+			continue
+		}
+		if sql[i] != q {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == q {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(sql)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isOperatorByte(c byte) bool {
+	return c == '=' || c == '<' || c == '>' || c == '!'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
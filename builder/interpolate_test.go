@@ -0,0 +1,124 @@
+package builder
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failedValuer struct{}
+
+func (failedValuer) Value() (driver.Value, error) {
+	return nil, errors.New("invalid value")
+}
+
+func TestInterpolate(t *testing.T) {
+	ass := assert.New(t)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	name := "deen"
+	var nilName *string
+	var data = []struct {
+		sql    string
+		args   []interface{}
+		masked []string
+		out    string
+	}{
+		{
+			"SELECT * FROM user WHERE (name=? AND age>=? AND score<? AND vip=?)",
+			[]interface{}{"O'Brien\\", int8(18), 1.5, true},
+			nil,
+			`SELECT * FROM user WHERE (name='O\'Brien\\' AND age>=18 AND score<1.5 AND vip=TRUE)`,
+		},
+		{
+			"SELECT * FROM user WHERE (created_at>? AND avatar=? AND deleted_at=? AND nick=? AND email=? AND title=? AND memo=?)",
+			[]interface{}{created, []byte{0xff, 0x01}, nil, sql.NullString{}, sql.NullString{String: "a\nb", Valid: true}, &name, nilName},
+			nil,
+			`SELECT * FROM user WHERE (created_at>'2020-01-02 03:04:05.6' AND avatar=X'ff01' AND deleted_at=NULL AND nick=NULL AND email='a\nb' AND title='deen' AND memo=NULL)`,
+		},
+		{
+			"SELECT * FROM user WHERE (u.password=? AND token IN (?,?) AND age BETWEEN ? AND ? AND note='?') LIMIT ?,?",
+			[]interface{}{"secret", "t1", "t2", 18, 30, 0, 10},
+			[]string{"password", "TOKEN"},
+			"SELECT * FROM user WHERE (u.password='xxxxx' AND token IN ('xxxxx','xxxxx') AND age BETWEEN 18 AND 30 AND note='?') LIMIT 0,10",
+		},
+		{
+			"SELECT * FROM user u INNER JOIN account a ON (a.uid=u.id) WHERE (a.password=? AND u.password=?)",
+			[]interface{}{"s1", "s2"},
+			[]string{"a.password"},
+			"SELECT * FROM user u INNER JOIN account a ON (a.uid=u.id) WHERE (a.password='xxxxx' AND u.password='s2')",
+		},
+		{
+			"INSERT INTO user (name,password,age) VALUES (?,?,?),(?,?,NOW()) ON DUPLICATE KEY UPDATE password=VALUES(password),age=?",
+			[]interface{}{"deen", "p1", 18, "caibirdme", "p2", 20},
+			[]string{"password"},
+			"INSERT INTO user (name,password,age) VALUES ('deen','xxxxx',18),('caibirdme','xxxxx',NOW()) ON DUPLICATE KEY UPDATE password=VALUES(password),age=20",
+		},
+		{
+			"UPDATE user SET password=?,age=? WHERE (id=?)",
+			[]interface{}{"secret", 18, uint(1)},
+			[]string{"password"},
+			"UPDATE user SET password='xxxxx',age=18 WHERE (id=1)",
+		},
+		{
+			"UPDATE u SET password=SHA2(?,256),age=? WHERE id=? LIMIT ?",
+			[]interface{}{"secret", 18, 1, 10},
+			[]string{"password"},
+			"UPDATE u SET password=SHA2('xxxxx',256),age=18 WHERE id=1 LIMIT 10",
+		},
+		{
+			"SELECT * FROM u WHERE (password = CONCAT(salt,UPPER(?)) AND name LIKE CONCAT(?,'%') AND id IN (SELECT uid FROM t WHERE token<>?))",
+			[]interface{}{"secret", "deen", "t1"},
+			[]string{"password", "token"},
+			"SELECT * FROM u WHERE (password = CONCAT(salt,UPPER('xxxxx')) AND name LIKE CONCAT('deen','%') AND id IN (SELECT uid FROM t WHERE token<>'xxxxx'))",
+		},
+	}
+	for _, tc := range data {
+		out, err := Interpolate(tc.sql, tc.args, tc.masked...)
+		ass.NoError(err, tc.sql)
+		ass.Equal(tc.out, out)
+	}
+
+	cond, vals, err := BuildUpdate("user", map[string]interface{}{"id": 1}, map[string]interface{}{"password": Expr("SHA2(?,256)", "secret")})
+	ass.NoError(err)
+	out, err := Interpolate(cond, vals, "password")
+	ass.NoError(err)
+	ass.Equal("UPDATE user SET password=SHA2('xxxxx',256) WHERE (id=1)", out)
+
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).BuildInsert("user", []map[string]interface{}{{"name": "O'Brien", "password": "secret", "avatar": []byte{1}}})
+	ass.NoError(err)
+	out, err = New(PostgreSQL).Interpolate(cond, vals, "password")
+	ass.NoError(err)
+	ass.Equal(`INSERT INTO "user" ("avatar","name","password") VALUES ('\x01','O''Brien','xxxxx')`, out)
+	out, err = New(PostgreSQL).Interpolate(`SELECT * FROM "user" WHERE ("name"=$2 AND "age">$1 AND note='$1')`, []interface{}{18, "deen"}, "name")
+	ass.NoError(err)
+	ass.Equal(`SELECT * FROM "user" WHERE ("name"='xxxxx' AND "age">18 AND note='$1')`, out)
+	out, err = New(SQLite).Interpolate("SELECT * FROM user WHERE (name=? AND score>? AND avatar=?)", []interface{}{"it's", math.Inf(1), []byte{1}})
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM user WHERE (name='it''s' AND score>'+Inf' AND avatar=X'01')", out)
+	out, err = Interpolate("SELECT * FROM user WHERE (status=?)", []interface{}{IsNull})
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM user WHERE (status=NULL)", out)
+
+	out, err = Interpolate(`SELECT * FROM user WHERE a='it\'s ?' AND c="\"?" AND b=?`, []interface{}{1})
+	ass.NoError(err)
+	ass.Equal(`SELECT * FROM user WHERE a='it\'s ?' AND c="\"?" AND b=1`, out, "backslashes escape in MySQL")
+	out, err = New(PostgreSQL).Interpolate(`SELECT * FROM user WHERE a='C:\' AND b=$1`, []interface{}{1})
+	ass.NoError(err)
+	ass.Equal(`SELECT * FROM user WHERE a='C:\' AND b=1`, out, "but not in PostgreSQL")
+
+	_, err = Interpolate("SELECT * FROM user WHERE (id=?)", nil)
+	ass.Equal(errInterpolateArgs, err)
+	_, err = Interpolate("SELECT * FROM user WHERE (id=?)", []interface{}{1, 2})
+	ass.Equal(errInterpolateArgs, err)
+	_, err = New(PostgreSQL).Interpolate("SELECT * FROM user WHERE (id=$2)", []interface{}{1})
+	ass.Equal(errInterpolateArgs, err)
+	_, err = Interpolate("SELECT * FROM user WHERE (id=?)", []interface{}{failedValuer{}})
+	ass.EqualError(err, "invalid value")
+	_, err = Interpolate("SELECT * FROM user WHERE (id=?)", []interface{}{struct{}{}})
+	ass.EqualError(err, "[builder] can't interpolate the value of type struct {}")
+}
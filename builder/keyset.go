@@ -0,0 +1,291 @@
+package builder
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	errKeysetValueType  = errors.New(`[builder] the value of "_keyset" must be of Keyset type`)
+	errKeysetEmpty      = errors.New("[builder] the keys of a keyset can't be empty")
+	errKeysetColumn     = errors.New("[builder] the column of a sort key can't be empty")
+	errKeysetValues     = errors.New("[builder] the values after which a keyset starts must match its keys")
+	errKeysetOrderBy    = errors.New(`[builder] "_keyset" and "_orderby" can't be used together`)
+	errCursorValueType  = errors.New("[builder] the value of a cursor must be a number, string, bool, time.Time or []byte")
+	errCursorMalformed  = errors.New("[builder] malformed cursor")
+	errCursorValueCount = errors.New("[builder] the cursor doesn't match the keys of the keyset")
+)
+
+// SortKey is a column of a keyset and its direction
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// Keyset paginates by the values of the sort keys of the last row instead of an offset,
+// so that a deep page costs as much as the first one given an index on the keys.
+// The keys must identify a row uniquely and can't be NULL, the last one is usually the primary key.
+//
+// As the value of "_keyset", it sets the ORDER BY clause by Keys and, if After isn't empty,
+// adds the condition that a row comes after them: (a,b)>(?,?) if the keys share the direction,
+// otherwise (a>? OR (a=? AND b<?)).
+type Keyset struct {
+	Keys []SortKey
+	// After are the values of Keys of the last row of the previous page, empty for the first page
+	After []interface{}
+}
+
+// NewKeyset returns a Keyset starting after the row of cursor, which is returned by EncodeCursor.
+// An empty cursor means the first page.
+func NewKeyset(cursor string, keys ...SortKey) (Keyset, error) {
+	k := Keyset{Keys: keys}
+	if "" == cursor {
+		return k, nil
+	}
+	after, err := DecodeCursor(cursor)
+	if nil != err {
+		return Keyset{}, err
+	}
+	if len(after) != len(keys) {
+		return Keyset{}, errCursorValueCount
+	}
+	k.After = after
+	return k, nil
+}
+
+func resolveKeyset(val interface{}) (Keyset, error) {
+	var k Keyset
+	switch v := val.(type) {
+	case Keyset:
+		k = v
+	case *Keyset:
+		if nil == v {
+			return Keyset{}, errKeysetValueType
+		}
+		k = *v
+	default:
+		return Keyset{}, errKeysetValueType
+	}
+	if len(k.Keys) == 0 {
+		return Keyset{}, errKeysetEmpty
+	}
+	for _, key := range k.Keys {
+		if "" == strings.TrimSpace(key.Column) {
+			return Keyset{}, errKeysetColumn
+		}
+	}
+	if len(k.After) > 0 && len(k.After) != len(k.Keys) {
+		return Keyset{}, errKeysetValues
+	}
+	return k, nil
+}
+
+// orderBy returns the ORDER BY items of the keys, they're quoted together with the statement
+func (k Keyset) orderBy() string {
+	items := make([]string, len(k.Keys))
+	for i, key := range k.Keys {
+		items[i] = key.Column
+		if key.Desc {
+			items[i] += " DESC"
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+type keysetComparable struct {
+	cond string
+	vals []interface{}
+}
+
+func (k keysetComparable) Build() ([]string, []interface{}) {
+	return []string{k.cond}, k.vals
+}
+
+// buildKeyset returns the condition that a row comes after k.After, or nil on the first page
+func (b *Builder) buildKeyset(k Keyset) (Comparable, error) {
+	if len(k.After) == 0 {
+		return nil, nil
+	}
+	columns := make([]string, len(k.Keys))
+	sameDirection := true
+	for i, key := range k.Keys {
+		column, err := b.quoteIdent(key.Column)
+		if nil != err {
+			return nil, err
+		}
+		columns[i] = column
+		sameDirection = sameDirection && key.Desc == k.Keys[0].Desc
+	}
+	if sameDirection {
+		op := ">"
+		if k.Keys[0].Desc {
+			op = "<"
+		}
+		if len(columns) == 1 {
+			return keysetComparable{cond: columns[0] + op + "?", vals: k.After}, nil
+		}
+		cond := fmt.Sprintf("(%s)%s(%s)", strings.Join(columns, ","), op, strings.Repeat("?,", len(columns)-1)+"?")
+		return keysetComparable{cond: cond, vals: k.After}, nil
+	}
+	// row constructors can't compare in mixed directions, expand them
+	var ors []string
+	var vals []interface{}
+	for i, key := range k.Keys {
+		op := ">?"
+		if key.Desc {
+			op = "<?"
+		}
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, columns[j]+"=?")
+			vals = append(vals, k.After[j])
+		}
+		ands = append(ands, columns[i]+op)
+		vals = append(vals, k.After[i])
+		if 0 == i {
+			ors = append(ors, ands[0])
+		} else {
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+	}
+	return keysetComparable{cond: "(" + strings.Join(ors, " OR ") + ")", vals: vals}, nil
+}
+
+// Keyset sets the ORDER BY clause and the condition of the page, it's the same as "_keyset"
+func (s *SelectBuilder) Keyset(k Keyset) *SelectBuilder {
+	k, err := resolveKeyset(k)
+	if nil != err {
+		s.err = err
+		return s
+	}
+	cond, err := s.builder.buildKeyset(k)
+	if nil != err {
+		s.err = err
+		return s
+	}
+	s.stmt.orderBy = k.orderBy()
+	if nil != cond {
+		s.stmt.where = append(s.stmt.where, cond)
+	}
+	return s
+}
+
+type cursorValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v"`
+}
+
+// EncodeCursor returns an opaque URL-safe token of the values of the sort keys of the last row,
+// which is passed back to NewKeyset for the next page.
+// The values could be numbers, strings, bools, time.Time, []byte and driver.Valuer of them,
+// and they keep their types after DecodeCursor, except that integers become int64 or uint64
+// and floats become float64.
+// The token isn't signed, don't put anything secret in it.
+func EncodeCursor(values ...interface{}) (string, error) {
+	encoded := make([]cursorValue, len(values))
+	for i, value := range values {
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if nil != err {
+				return "", err
+			}
+			value = v
+		}
+		typ, v, err := cursorType(value)
+		if nil != err {
+			return "", err
+		}
+		raw, err := json.Marshal(v)
+		if nil != err {
+			return "", err
+		}
+		encoded[i] = cursorValue{Type: typ, Value: raw}
+	}
+	data, err := json.Marshal(encoded)
+	if nil != err {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func cursorType(value interface{}) (string, interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return "t", v.Format(time.RFC3339Nano), nil
+	case []byte:
+		return "x", v, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "i", rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "u", rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return "f", rv.Float(), nil
+	case reflect.String:
+		return "s", rv.String(), nil
+	case reflect.Bool:
+		return "b", rv.Bool(), nil
+	}
+	return "", nil, errCursorValueType
+}
+
+// DecodeCursor returns the values of a token returned by EncodeCursor
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if nil != err {
+		return nil, errCursorMalformed
+	}
+	var encoded []cursorValue
+	if err := json.Unmarshal(data, &encoded); nil != err {
+		return nil, errCursorMalformed
+	}
+	values := make([]interface{}, len(encoded))
+	for i, e := range encoded {
+		var err error
+		switch e.Type {
+		case "i":
+			var v int64
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "u":
+			var v uint64
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "f":
+			var v float64
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "s":
+			var v string
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "b":
+			var v bool
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "x":
+			var v []byte
+			err = json.Unmarshal(e.Value, &v)
+			values[i] = v
+		case "t":
+			var s string
+			if err = json.Unmarshal(e.Value, &s); nil == err {
+				values[i], err = time.Parse(time.RFC3339Nano, s)
+			}
+		default:
+			err = errCursorMalformed
+		}
+		if nil != err {
+			return nil, errCursorMalformed
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,121 @@
+package builder
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSelectKeyset(t *testing.T) {
+	var data = []struct {
+		keyset Keyset
+		cond   string
+		vals   []interface{}
+	}{
+		{
+			Keyset{Keys: []SortKey{{Column: "id"}}},
+			"SELECT id,name FROM user WHERE (status=?) ORDER BY id LIMIT ?,?",
+			[]interface{}{1, 0, 20},
+		},
+		{
+			Keyset{Keys: []SortKey{{Column: "id", Desc: true}}, After: []interface{}{100}},
+			"SELECT id,name FROM user WHERE (status=? AND id<?) ORDER BY id DESC LIMIT ?,?",
+			[]interface{}{1, 100, 0, 20},
+		},
+		{
+			Keyset{Keys: []SortKey{{Column: "created_at"}, {Column: "id"}}, After: []interface{}{"2020-01-01", 100}},
+			"SELECT id,name FROM user WHERE (status=? AND (created_at,id)>(?,?)) ORDER BY created_at,id LIMIT ?,?",
+			[]interface{}{1, "2020-01-01", 100, 0, 20},
+		},
+		{
+			Keyset{Keys: []SortKey{{Column: "score", Desc: true}, {Column: "age"}, {Column: "id", Desc: true}}, After: []interface{}{90, 18, 100}},
+			"SELECT id,name FROM user WHERE (status=? AND (score<? OR (score=? AND age>?) OR (score=? AND age=? AND id<?))) ORDER BY score DESC,age,id DESC LIMIT ?,?",
+			[]interface{}{1, 90, 90, 18, 90, 18, 100, 0, 20},
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		cond, vals, err := BuildSelect("user", map[string]interface{}{
+			"status":  1,
+			"_keyset": tc.keyset,
+			"_limit":  []uint{20},
+		}, []string{"id", "name"})
+		ass.NoError(err)
+		ass.Equal(tc.cond, cond)
+		ass.Equal(tc.vals, vals)
+	}
+
+	k := Keyset{Keys: []SortKey{{Column: "age", Desc: true}, {Column: "id"}}, After: []interface{}{18, 100}}
+	cond, vals, err := New(PostgreSQL).Quote(QuoteAll).BuildSelect("user", map[string]interface{}{"_keyset": &k}, nil)
+	ass.NoError(err)
+	ass.Equal(`SELECT * FROM "user" WHERE (("age"<$1 OR ("age"=$2 AND "id">$3))) ORDER BY "age" DESC,"id"`, cond)
+	ass.Equal([]interface{}{18, 18, 100}, vals)
+
+	cond, vals, err = New(PostgreSQL).Select("id").From("user").Where(Eq{"status": 1}).
+		Keyset(Keyset{Keys: []SortKey{{Column: "age"}, {Column: "id"}}, After: []interface{}{18, 100}}).Limit(20).Build()
+	ass.NoError(err)
+	ass.Equal("SELECT id FROM user WHERE (status=$1 AND (age,id)>($2,$3)) ORDER BY age,id LIMIT $4 OFFSET $5", cond)
+	ass.Equal([]interface{}{1, 18, 100, 20, 0}, vals)
+}
+
+func TestBuildSelectKeysetError(t *testing.T) {
+	var data = []struct {
+		keyset interface{}
+		err    error
+	}{
+		{"id", errKeysetValueType},
+		{(*Keyset)(nil), errKeysetValueType},
+		{Keyset{}, errKeysetEmpty},
+		{Keyset{Keys: []SortKey{{Column: " "}}}, errKeysetColumn},
+		{Keyset{Keys: []SortKey{{Column: "a"}, {Column: "b"}}, After: []interface{}{1}}, errKeysetValues},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		_, _, err := BuildSelect("user", map[string]interface{}{"_keyset": tc.keyset}, nil)
+		ass.Equal(tc.err, err, "%v", tc.keyset)
+	}
+	_, _, err := BuildSelect("user", map[string]interface{}{
+		"_keyset":  Keyset{Keys: []SortKey{{Column: "id"}}},
+		"_orderby": "name",
+	}, nil)
+	ass.Equal(errKeysetOrderBy, err)
+	_, _, err = Select().From("user").Keyset(Keyset{}).Build()
+	ass.Equal(errKeysetEmpty, err)
+}
+
+func TestCursor(t *testing.T) {
+	ass := assert.New(t)
+	type status int8
+	created := time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("CST", 8*3600))
+	token, err := EncodeCursor(created, 100, uint32(7), 1.5, "a&b", true, []byte{1, 2}, status(3), sql.NullString{String: "x", Valid: true})
+	ass.NoError(err)
+	ass.NotContains(token, "=", "URL-safe without padding")
+	values, err := DecodeCursor(token)
+	ass.NoError(err)
+	ass.True(created.Equal(values[0].(time.Time)))
+	ass.Equal([]interface{}{int64(100), uint64(7), 1.5, "a&b", true, []byte{1, 2}, int64(3), "x"}, values[1:])
+
+	_, err = EncodeCursor(nil)
+	ass.Equal(errCursorValueType, err)
+	_, err = EncodeCursor(sql.NullInt64{})
+	ass.Equal(errCursorValueType, err, "NULL is not supported")
+	for _, bad := range []string{"!", "bm90IGpzb24", "W3sidCI6InEiLCJ2IjoxfV0"} {
+		_, err = DecodeCursor(bad)
+		ass.Equal(errCursorMalformed, err, bad)
+	}
+
+	k, err := NewKeyset("", SortKey{Column: "id"})
+	ass.NoError(err)
+	ass.Empty(k.After)
+	token, err = EncodeCursor(18, 100)
+	ass.NoError(err)
+	k, err = NewKeyset(token, SortKey{Column: "age"}, SortKey{Column: "id"})
+	ass.NoError(err)
+	ass.Equal([]interface{}{int64(18), int64(100)}, k.After)
+	_, err = NewKeyset(token, SortKey{Column: "id"})
+	ass.Equal(errCursorValueCount, err)
+	_, err = NewKeyset("!", SortKey{Column: "id"})
+	ass.Equal(errCursorMalformed, err)
+}
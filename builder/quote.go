@@ -0,0 +1,214 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidIdentifier reports an identifier can't be quoted in QuoteStrict mode
+var ErrInvalidIdentifier = errors.New("[builder] invalid identifier")
+
+// QuoteMode tells a Builder how to treat table names, column names and
+// the select fields
+type QuoteMode int
+
+const (
+	// QuoteNone leaves the identifiers as they are, it's the default
+	QuoteNone QuoteMode = iota
+	// QuoteAll quotes the identifiers in the way of the dialect, expressions
+	// it doesn't understand are left as they are
+	QuoteAll
+	// QuoteStrict is the same as QuoteAll but it rejects the expressions
+	// it doesn't understand with ErrInvalidIdentifier
+	QuoteStrict
+)
+
+var (
+	validName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+	numberLiteral = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	// validQuoted is the content of a quoted name without the escaped quotes
+	validQuoted = regexp.MustCompile(`^[A-Za-z0-9_$ ]*$`)
+)
+
+func invalidIdentifier(s string) error {
+	return fmt.Errorf("%w: %q", ErrInvalidIdentifier, s)
+}
+
+// quoteExpr quotes an identifier or a simple expression:
+// name, table.column, table.*, count(*), sum(t.price) AS total, DISTINCT name
+func quoteExpr(d Dialect, expr string, strict bool) (string, error) {
+	expr = strings.TrimSpace(expr)
+	body, alias := splitAlias(expr)
+	quoted, ok := quoteTerm(d, body)
+	if ok && "" != alias {
+		quotedAlias, aliasOK := quoteName(d, alias)
+		quoted, ok = quoted+" AS "+quotedAlias, aliasOK
+	}
+	if !ok {
+		if strict {
+			return "", invalidIdentifier(expr)
+		}
+		return expr, nil
+	}
+	return quoted, nil
+}
+
+// quoteList quotes a comma separated list such as the value of _groupby,
+// each item could be followed by ASC or DESC if withDirection is true
+func quoteList(d Dialect, list string, strict, withDirection bool) (string, error) {
+	items := splitTopLevel(list, ',')
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		var direction string
+		if idx := strings.LastIndexByte(item, ' '); withDirection && idx != -1 {
+			switch strings.ToUpper(item[idx+1:]) {
+			case "ASC", "DESC":
+				item, direction = strings.TrimSpace(item[:idx]), item[idx:]
+			}
+		}
+		quoted, ok := quoteTerm(d, item)
+		if !ok {
+			if strict {
+				return "", invalidIdentifier(item)
+			}
+			quoted = item
+		}
+		items[i] = quoted + direction
+	}
+	return strings.Join(items, ","), nil
+}
+
+// splitAlias splits `expr [AS] alias` into expr and alias
+func splitAlias(expr string) (string, string) {
+	parts := splitTopLevel(expr, ' ')
+	if len(parts) < 2 {
+		return expr, ""
+	}
+	n := len(parts)
+	alias := parts[n-1]
+	body := parts[:n-1]
+	if len(body) > 1 && strings.EqualFold(body[len(body)-1], "AS") {
+		body = body[:len(body)-1]
+	}
+	if len(body) == 1 && strings.EqualFold(body[0], "DISTINCT") {
+		return expr, ""
+	}
+	return strings.Join(body, " "), alias
+}
+
+func quoteTerm(d Dialect, term string) (string, bool) {
+	term = strings.TrimSpace(term)
+	if len(term) > 9 && strings.EqualFold(term[:9], "DISTINCT ") {
+		quoted, ok := quoteTerm(d, term[9:])
+		return "DISTINCT " + quoted, ok
+	}
+	if "*" == term || numberLiteral.MatchString(term) {
+		return term, true
+	}
+	if idx := strings.IndexByte(term, '('); idx > 0 && strings.HasSuffix(term, ")") {
+		fn := strings.TrimSpace(term[:idx])
+		if !validName.MatchString(fn) {
+			return term, false
+		}
+		inner := strings.TrimSpace(term[idx+1 : len(term)-1])
+		if "" == inner {
+			return fn + "()", true
+		}
+		args := splitTopLevel(inner, ',')
+		for i := range args {
+			arg, ok := quoteTerm(d, args[i])
+			if !ok {
+				return term, false
+			}
+			args[i] = arg
+		}
+		return fn + "(" + strings.Join(args, ",") + ")", true
+	}
+	parts := splitTopLevel(term, '.')
+	for i, part := range parts {
+		if "*" == part && i == len(parts)-1 && i > 0 {
+			continue
+		}
+		quoted, ok := quoteName(d, part)
+		if !ok {
+			return term, false
+		}
+		parts[i] = quoted
+	}
+	return strings.Join(parts, "."), true
+}
+
+// quoteName quotes a single name, names which are already quoted in the way of d are kept
+func quoteName(d Dialect, name string) (string, bool) {
+	if isQuoted(d, name) {
+		return name, true
+	}
+	if !validName.MatchString(name) {
+		return name, false
+	}
+	return d.QuoteIdent(name), true
+}
+
+// isQuoted reports whether name is a single name quoted in the way of d, the quote inside it
+// must be escaped by doubling, so that "a",(SELECT ...),"b" isn't taken as one.
+// Names quoted by the other dialects are not, such as `a` for PostgreSQL
+func isQuoted(d Dialect, name string) bool {
+	if len(name) < 3 {
+		return false
+	}
+	q := d.QuoteIdent("a")[0]
+	if name[0] != q || name[len(name)-1] != q {
+		return false
+	}
+	inner := name[1 : len(name)-1]
+	for i := 0; i < len(inner); i++ {
+		if inner[i] != q {
+			continue
+		}
+		if i+1 == len(inner) || inner[i+1] != q {
+			return false
+		}
+		i++
+	}
+	return validQuoted.MatchString(strings.Replace(inner, string(q)+string(q), "", -1))
+}
+
+// splitTopLevel splits s by sep which is neither in parentheses nor quoted,
+// empty parts are dropped when sep is a space
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	var depth int
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, s[start:])
+	if sep != ' ' {
+		return parts
+	}
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if "" != part {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return nonEmpty
+}
@@ -0,0 +1,122 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteExpr(t *testing.T) {
+	var data = []struct {
+		in       string
+		mysql    string
+		postgres string
+		strict   bool
+	}{
+		{"name", "`name`", `"name"`, true},
+		{" order ", "`order`", `"order"`, true},
+		{"t.key", "`t`.`key`", `"t"."key"`, true},
+		{"t.*", "`t`.*", `"t".*`, true},
+		{"*", "*", "*", true},
+		{"`group`", "`group`", "`group`", true},
+		{"count(*)", "count(*)", "count(*)", true},
+		{"count(price) as total", "count(`price`) AS `total`", `count("price") AS "total"`, true},
+		{"sum(t.price) total", "sum(`t`.`price`) AS `total`", `sum("t"."price") AS "total"`, true},
+		{"count(distinct name)", "count(DISTINCT `name`)", `count(DISTINCT "name")`, true},
+		{"distinct name", "DISTINCT `name`", `DISTINCT "name"`, true},
+		{"round(price, 2)", "round(`price`,2)", `round("price",2)`, true},
+		{"tb t", "`tb` AS `t`", `"tb" AS "t"`, true},
+		{"a+b", "a+b", "a+b", false},
+		{"id; drop table tb", "id; drop table tb", "id; drop table tb", false},
+		{"price * 2 AS double", "price * 2 AS double", "price * 2 AS double", false},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		out, err := quoteExpr(MySQL, tc.in, false)
+		ass.NoError(err)
+		ass.Equal(tc.mysql, out, "in:%s", tc.in)
+		out, err = quoteExpr(PostgreSQL, tc.in, false)
+		ass.NoError(err)
+		ass.Equal(tc.postgres, out, "in:%s", tc.in)
+		out, err = quoteExpr(MySQL, tc.in, true)
+		if tc.strict {
+			ass.NoError(err, "in:%s", tc.in)
+			ass.Equal(tc.mysql, out)
+		} else {
+			ass.True(errors.Is(err, ErrInvalidIdentifier), "in:%s", tc.in)
+		}
+	}
+}
+
+func TestQuoteList(t *testing.T) {
+	ass := assert.New(t)
+	out, err := quoteList(MySQL, "age DESC, t.score asc,name", false, true)
+	ass.NoError(err)
+	ass.Equal("`age` DESC,`t`.`score` asc,`name`", out)
+	out, err = quoteList(PostgreSQL, "department,field(id, 3, 1)", false, false)
+	ass.NoError(err)
+	ass.Equal(`"department",field("id",3,1)`, out)
+	out, err = quoteList(MySQL, "rand() > 0.5", false, true)
+	ass.NoError(err)
+	ass.Equal("rand() > 0.5", out)
+	_, err = quoteList(MySQL, "rand() > 0.5", true, true)
+	ass.True(errors.Is(err, ErrInvalidIdentifier))
+}
+
+func TestBuilderQuote(t *testing.T) {
+	ass := assert.New(t)
+	b := New(MySQL).Quote(QuoteAll)
+	fields := []string{"id", "order", "count(*) as total"}
+	cond, vals, err := b.BuildSelect("tb", map[string]interface{}{
+		"key":       1,
+		"t.group >": 2,
+		"_or": []map[string]interface{}{
+			{"desc": 3},
+			{"desc like": "%a"},
+		},
+		"_groupby": "order",
+		"_having":  map[string]interface{}{"total >": 4},
+		"_orderby": "order desc",
+	}, fields)
+	ass.NoError(err)
+	ass.Equal("SELECT `id`,`order`,count(*) AS `total` FROM `tb` WHERE (((`desc`=?) OR (`desc` LIKE ?)) AND `key`=? AND `t`.`group`>?) GROUP BY `order` HAVING (`total`>?) ORDER BY `order` desc", cond)
+	ass.Equal([]interface{}{3, "%a", 1, 2, 4}, vals)
+	ass.Equal([]string{"id", "order", "count(*) as total"}, fields, "select fields shouldn't be modified")
+
+	pg := New(PostgreSQL).Quote(QuoteStrict)
+	cond, vals, err = pg.BuildUpdate("user", map[string]interface{}{"key in": []int{1, 2}}, map[string]interface{}{"order": 3})
+	ass.NoError(err)
+	ass.Equal(`UPDATE "user" SET "order"=$1 WHERE ("key" IN ($2,$3))`, cond)
+	ass.Equal([]interface{}{3, 1, 2}, vals)
+
+	cond, vals, err = pg.BuildInsert("user", []map[string]interface{}{{"order": 1, "key": 2}})
+	ass.NoError(err)
+	ass.Equal(`INSERT INTO "user" ("key","order") VALUES ($1,$2)`, cond)
+	ass.Equal([]interface{}{2, 1}, vals)
+
+	cond, _, err = pg.BuildDelete("user", map[string]interface{}{"group": 1})
+	ass.NoError(err)
+	ass.Equal(`DELETE FROM "user" WHERE ("group"=$1)`, cond)
+
+	_, _, err = pg.BuildSelect("tb", map[string]interface{}{"a or 1=1 --": 1}, nil)
+	ass.Error(err)
+	_, _, err = pg.BuildSelect("tb", map[string]interface{}{"a": 1}, []string{"(select password from user)"})
+	ass.True(errors.Is(err, ErrInvalidIdentifier))
+	_, _, err = pg.BuildUpdate("tb", nil, map[string]interface{}{"a=1,b": 1})
+	ass.True(errors.Is(err, ErrInvalidIdentifier))
+	_, _, err = pg.BuildSelect("tb", map[string]interface{}{"a": 1}, []string{`"a",(select password from users),"b"`})
+	ass.True(errors.Is(err, ErrInvalidIdentifier))
+	_, _, err = pg.BuildUpdate("tb", nil, map[string]interface{}{`"a"=1,"b"`: 2})
+	ass.True(errors.Is(err, ErrInvalidIdentifier))
+	cond, _, err = pg.BuildSelect("tb", nil, []string{`"my ""col"""`})
+	ass.NoError(err)
+	ass.Equal(`SELECT "my ""col""" FROM "tb"`, cond, "escaped quotes are kept")
+	_, _, err = pg.BuildSelect("tb", nil, []string{"`odd`"})
+	ass.True(errors.Is(err, ErrInvalidIdentifier), "quoted by MySQL")
+	cond, _, err = New(MySQL).Quote(QuoteStrict).BuildSelect("tb", nil, []string{"`my ``col```"})
+	ass.NoError(err)
+	ass.Equal("SELECT `my ``col``` FROM `tb`", cond)
+	_, _, err = New(MySQL).Quote(QuoteStrict).BuildSelect("tb", nil, []string{`"odd"`})
+	ass.True(errors.Is(err, ErrInvalidIdentifier), "quoted by PostgreSQL")
+}
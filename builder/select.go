@@ -0,0 +1,205 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errSelectNoTable        = errors.New("[builder] the table of the select statement is empty, call From first")
+	errSelectOffsetNoLimit  = errors.New("[builder] Offset must be used together with Limit")
+	errSelectMultipleSource = errors.New("[builder] From and FromSubquery can't be used together")
+)
+
+// SelectBuilder builds a SELECT statement step by step.
+// It produces the same statement as BuildSelect, while the conditions
+// are the Comparable types such as Eq, In, Between, OrWhere and NestWhere.
+// Keys of the Comparables are put into the statement as they are even if
+// the Builder quotes identifiers.
+type SelectBuilder struct {
+	builder *Builder
+	stmt    selectStmt
+	offset  uint
+	count   *uint
+	err     error
+}
+
+// Select starts a MySQL SELECT statement, no fields means *
+func Select(fields ...string) *SelectBuilder {
+	return defaultBuilder.Select(fields...)
+}
+
+// Select starts a SELECT statement in the dialect of b, no fields means *
+func (b *Builder) Select(fields ...string) *SelectBuilder {
+	return &SelectBuilder{
+		builder: b,
+		stmt:    selectStmt{fields: fields},
+	}
+}
+
+// Field appends a raw expression to the select fields, it's never quoted:
+// Field(Expr("IF(age>?,1,0) AS adult", 18))
+func (s *SelectBuilder) Field(expr Raw) *SelectBuilder {
+	s.stmt.exprFields = append(s.stmt.exprFields, expr)
+	return s
+}
+
+// From sets the table
+func (s *SelectBuilder) From(table string) *SelectBuilder {
+	if nil != s.stmt.from {
+		s.err = errSelectMultipleSource
+	}
+	s.stmt.table = table
+	return s
+}
+
+// FromSubquery selects from a derived table
+func (s *SelectBuilder) FromSubquery(from *Subquery, alias string) *SelectBuilder {
+	switch {
+	case "" != s.stmt.table && nil == s.stmt.from:
+		s.err = errSelectMultipleSource
+	case nil == from:
+		s.err = errNilSubquery
+	case "" == strings.TrimSpace(alias):
+		s.err = errSubqueryAlias
+	}
+	s.stmt.from = from
+	s.stmt.table = alias
+	return s
+}
+
+// Join adds a JOIN clause, the conditions are joined by AND
+func (s *SelectBuilder) Join(typ JoinType, table string, on ...Comparable) *SelectBuilder {
+	s.stmt.joins = append(s.stmt.joins, joinClause{typ: typ, table: table, on: on})
+	return s
+}
+
+// Where adds conditions, all of the conditions are joined by AND
+func (s *SelectBuilder) Where(conditions ...Comparable) *SelectBuilder {
+	s.stmt.where = append(s.stmt.where, conditions...)
+	return s
+}
+
+// GroupBy sets the GROUP BY clause
+func (s *SelectBuilder) GroupBy(fields ...string) *SelectBuilder {
+	s.stmt.groupBy = strings.Join(fields, ",")
+	return s
+}
+
+// Having adds conditions of the HAVING clause, it's ignored without GroupBy
+func (s *SelectBuilder) Having(conditions ...Comparable) *SelectBuilder {
+	s.stmt.having = append(s.stmt.having, conditions...)
+	return s
+}
+
+// OrderBy sets the ORDER BY clause, each item could be followed by ASC or DESC:
+// OrderBy("age DESC", "id")
+func (s *SelectBuilder) OrderBy(items ...string) *SelectBuilder {
+	s.stmt.orderBy = strings.Join(items, ",")
+	return s
+}
+
+// Limit sets the max number of rows
+func (s *SelectBuilder) Limit(count uint) *SelectBuilder {
+	s.count = &count
+	return s
+}
+
+// Offset sets the number of rows to skip, it requires Limit
+func (s *SelectBuilder) Offset(offset uint) *SelectBuilder {
+	s.offset = offset
+	return s
+}
+
+// ForUpdate is the same as "_lockMode": "exclusive"
+func (s *SelectBuilder) ForUpdate() *SelectBuilder {
+	s.stmt.lockMode = "exclusive"
+	return s
+}
+
+// ForShare is the same as "_lockMode": "share"
+func (s *SelectBuilder) ForShare() *SelectBuilder {
+	s.stmt.lockMode = "share"
+	return s
+}
+
+func (s *SelectBuilder) resolve() (selectStmt, error) {
+	if nil != s.err {
+		return selectStmt{}, s.err
+	}
+	stmt := s.stmt
+	if "" == strings.TrimSpace(stmt.table) {
+		return selectStmt{}, errSelectNoTable
+	}
+	if err := checkConditions(stmt.where); nil != err {
+		return selectStmt{}, err
+	}
+	if err := checkConditions(stmt.having); nil != err {
+		return selectStmt{}, err
+	}
+	for _, join := range stmt.joins {
+		if err := checkConditions(join.on); nil != err {
+			return selectStmt{}, err
+		}
+	}
+	if "" == stmt.groupBy {
+		stmt.having = nil
+	}
+	if nil != s.count {
+		stmt.limit = &eleLimit{begin: s.offset, step: *s.count}
+	} else if s.offset > 0 {
+		return selectStmt{}, errSelectOffsetNoLimit
+	}
+	return stmt, nil
+}
+
+func (s *SelectBuilder) build() (string, []interface{}, error) {
+	stmt, err := s.resolve()
+	if nil != err {
+		return "", nil, err
+	}
+	return s.builder.buildSelect(stmt)
+}
+
+// Build returns the statement and its arguments
+func (s *SelectBuilder) Build() (string, []interface{}, error) {
+	return s.builder.rebind(s.build())
+}
+
+// Subquery returns the statement as a Subquery
+func (s *SelectBuilder) Subquery() (*Subquery, error) {
+	return NewSubquery(s.build())
+}
+
+// checkConditions rejects the conditions which would vanish from the statement,
+// such as Exists(nil), rather than matching every row
+func checkConditions(conditions []Comparable) error {
+	for _, cond := range conditions {
+		var err error
+		switch c := cond.(type) {
+		case existsComparable:
+			if len(c.subs) == 0 {
+				return errNilSubquery
+			}
+			_, err = resolveSubqueries(c.subs)
+		case NestWhere:
+			err = checkConditions(c)
+		case OrWhere:
+			err = checkConditions(c)
+		}
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists is the condition EXISTS (subquery), a nil subquery fails the statement
+func Exists(subs ...*Subquery) Comparable {
+	return existsComparable{subs: subs}
+}
+
+// NotExists is the condition NOT EXISTS (subquery), a nil subquery fails the statement
+func NotExists(subs ...*Subquery) Comparable {
+	return existsComparable{subs: subs, not: true}
+}
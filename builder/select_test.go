@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderSameAsBuildSelect(t *testing.T) {
+	ass := assert.New(t)
+	where := map[string]interface{}{
+		"city":          "Beijing",
+		"age >":         18,
+		"score in":      []interface{}{1, 2},
+		"level between": []interface{}{3, 5},
+		"_or": []map[string]interface{}{
+			{"role": "admin"},
+			{"role": "owner", "vip >=": 2},
+		},
+		"_groupby":  "department",
+		"_having":   map[string]interface{}{"total >": 10},
+		"_orderby":  "total desc,id",
+		"_limit":    []uint{20, 10},
+		"_lockMode": "exclusive",
+	}
+	fields := []string{"department", "count(*) as total"}
+	expectCond, expectVals, err := BuildSelect("user", where, fields)
+	ass.NoError(err)
+
+	cond, vals, err := Select("department", "count(*) as total").
+		From("user").
+		Where(
+			OrWhere{
+				NestWhere{Eq{"role": "admin"}},
+				NestWhere{Eq{"role": "owner"}, Gte{"vip": 2}},
+			},
+			Eq{"city": "Beijing"},
+			In{"score": {1, 2}},
+			Gt{"age": 18},
+			Between{"level": {3, 5}},
+		).
+		GroupBy("department").
+		Having(Gt{"total": 10}).
+		OrderBy("total desc", "id").
+		Limit(10).
+		Offset(20).
+		ForUpdate().
+		Build()
+	ass.NoError(err)
+	ass.Equal(expectCond, cond)
+	ass.Equal(expectVals, vals)
+}
+
+func TestSelectBuilder(t *testing.T) {
+	ass := assert.New(t)
+	cond, vals, err := Select().From("tb").Build()
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM tb", cond)
+	ass.Nil(vals)
+
+	sub, err := Select("uid").From("orders").Where(Eq{"status": "paid"}).Subquery()
+	ass.NoError(err)
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).Select("u.name", "o.total").
+		From("user u").
+		Join(LeftJoin, "orders o", Eq{"o.uid": Col("u.id")}, Gt{"o.total": 100}).
+		Where(Eq{"u.id": sub}, Exists(sub)).
+		Having(Gt{"total": 1}).
+		Limit(10).
+		ForShare().
+		Build()
+	ass.NoError(err)
+	ass.Equal(`SELECT "u"."name","o"."total" FROM "user" AS "u" LEFT JOIN "orders" AS "o" ON (o.uid=u.id AND o.total>$1) `+
+		`WHERE (u.id=(SELECT uid FROM orders WHERE (status=$2)) AND EXISTS (SELECT uid FROM orders WHERE (status=$3))) LIMIT $4 OFFSET $5 FOR SHARE`, cond)
+	ass.Equal([]interface{}{100, "paid", "paid", 10, 0}, vals)
+
+	cond, vals, err = Select("uid", "total").FromSubquery(sub, "t").Where(NotExists(sub)).Build()
+	ass.NoError(err)
+	ass.Equal("SELECT uid,total FROM (SELECT uid FROM orders WHERE (status=?)) AS t WHERE (NOT EXISTS (SELECT uid FROM orders WHERE (status=?)))", cond)
+	ass.Equal([]interface{}{"paid", "paid"}, vals)
+
+	_, _, err = Select("a").Build()
+	ass.Equal(errSelectNoTable, err)
+	_, _, err = Select("a").From("tb").Offset(10).Build()
+	ass.Equal(errSelectOffsetNoLimit, err)
+	_, _, err = Select("a").From("tb").FromSubquery(sub, "t").Build()
+	ass.Equal(errSelectMultipleSource, err)
+	_, _, err = Select("a").FromSubquery(nil, "t").Build()
+	ass.Equal(errNilSubquery, err)
+	_, _, err = Select("a").From("tb").Join("FULL JOIN", "tb2", Eq{"a": Col("b")}).Build()
+	ass.Equal(errJoinType, err)
+	_, _, err = New(SQLite).Select().From("tb").ForUpdate().Build()
+	ass.Error(err)
+	_, _, err = Select().From("tb").Where(Exists(nil)).Build()
+	ass.Equal(errNilSubquery, err, "the filter mustn't vanish")
+	_, _, err = Select().From("tb").Where(OrWhere{Eq{"a": 1}, NestWhere{NotExists()}}).Build()
+	ass.Equal(errNilSubquery, err)
+	_, _, err = Select().From("tb").Join(InnerJoin, "tb2", Exists(sub, nil)).Build()
+	ass.Equal(errNilSubquery, err)
+	_, err = Select().From("tb").GroupBy("a").Having(NotExists(nil)).Subquery()
+	ass.Equal(errNilSubquery, err)
+}
@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/didi/gendry/scanner"
+)
+
+var (
+	errStructColumnsNotMatch = errors.New("[builder] the structs have different columns because of omitempty")
+	errStructNoPrimaryKey    = errors.New("[builder] the struct has no non-empty field tagged with pk")
+	errStructNothingToUpdate = errors.New("[builder] the struct has no field to update")
+)
+
+// BuildInsertStruct is the same as BuildInsert but inserts a struct or a slice of structs.
+// The columns are named by the tag used by scanner.Scan, which is ddb by default.
+// Fields tagged with readonly or autoincr are never inserted,
+// fields tagged with omitempty are skipped if they hold the zero value:
+//
+//	type User struct {
+//		ID        int64     `ddb:"id,pk,autoincr"`
+//		Name      string    `ddb:"name"`
+//		Nickname  *string   `ddb:"nickname,omitempty"`
+//		CreatedAt time.Time `ddb:"created_at,readonly"`
+//	}
+func BuildInsertStruct(table string, data interface{}) (string, []interface{}, error) {
+	return defaultBuilder.BuildInsertStruct(table, data)
+}
+
+// BuildInsertStruct is the same as the package-level BuildInsertStruct but in the dialect of b
+func (b *Builder) BuildInsertStruct(table string, data interface{}) (string, []interface{}, error) {
+	rows, err := structInsertRows(data)
+	if nil != err {
+		return "", nil, err
+	}
+	return b.BuildInsert(table, rows)
+}
+
+// BuildUpdateStruct updates the row whose primary key equals the fields tagged with pk,
+// with the other fields of data. Fields tagged with readonly or autoincr are never updated,
+// fields tagged with omitempty are skipped if they hold the zero value.
+func BuildUpdateStruct(table string, data interface{}) (string, []interface{}, error) {
+	return defaultBuilder.BuildUpdateStruct(table, data)
+}
+
+// BuildUpdateStruct is the same as the package-level BuildUpdateStruct but in the dialect of b
+func (b *Builder) BuildUpdateStruct(table string, data interface{}) (string, []interface{}, error) {
+	fields, err := scanner.Fields(data)
+	if nil != err {
+		return "", nil, err
+	}
+	where := make(map[string]interface{})
+	update := make(map[string]interface{})
+	for _, field := range fields {
+		switch {
+		case field.Options.PK:
+			if field.Empty {
+				return "", nil, errStructNoPrimaryKey
+			}
+			where[field.Column] = field.Value
+		case field.Options.ReadOnly || field.Options.AutoIncr:
+		case field.Options.OmitEmpty && field.Empty:
+		default:
+			update[field.Column] = field.Value
+		}
+	}
+	if len(where) == 0 {
+		return "", nil, errStructNoPrimaryKey
+	}
+	if len(update) == 0 {
+		return "", nil, errStructNothingToUpdate
+	}
+	return b.BuildUpdate(table, where, update)
+}
+
+func structInsertRows(data interface{}) ([]map[string]interface{}, error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		row, err := structInsertRow(data)
+		if nil != err {
+			return nil, err
+		}
+		return []map[string]interface{}{row}, nil
+	}
+	rows := make([]map[string]interface{}, v.Len())
+	for i := range rows {
+		row, err := structInsertRow(v.Index(i).Interface())
+		if nil != err {
+			return nil, err
+		}
+		if i > 0 && !sameColumns(row, rows[0]) {
+			return nil, errStructColumnsNotMatch
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// sameColumns reports whether the rows have the same columns,
+// omitempty fields may leave out different ones
+func sameColumns(row, first map[string]interface{}) bool {
+	if len(row) != len(first) {
+		return false
+	}
+	for column := range row {
+		if _, ok := first[column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func structInsertRow(data interface{}) (map[string]interface{}, error) {
+	fields, err := scanner.Fields(data)
+	if nil != err {
+		return nil, err
+	}
+	row := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if field.Options.ReadOnly || field.Options.AutoIncr || (field.Options.OmitEmpty && field.Empty) {
+			continue
+		}
+		row[field.Column] = field.Value
+	}
+	return row, nil
+}
@@ -0,0 +1,72 @@
+package builder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/didi/gendry/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+type structUser struct {
+	ID        int64     `ddb:"id,pk,autoincr"`
+	Name      string    `ddb:"name"`
+	Nickname  *string   `ddb:"nickname,omitempty"`
+	Age       *int      `ddb:"age"`
+	CreatedAt time.Time `ddb:"created_at,readonly"`
+	Password  string    `ddb:"-"`
+	Version   int       `ddb:"version,omitempty"`
+}
+
+func TestBuildInsertStruct(t *testing.T) {
+	ass := assert.New(t)
+	nickname := "dd"
+	age := 20
+	cond, vals, err := BuildInsertStruct("user", &structUser{ID: 1, Name: "deen", Nickname: &nickname, Age: &age, CreatedAt: time.Now(), Password: "x"})
+	ass.NoError(err)
+	ass.Equal("INSERT INTO user (age,name,nickname) VALUES (?,?,?)", cond)
+	ass.Equal([]interface{}{20, "deen", "dd"}, vals)
+
+	cond, vals, err = New(PostgreSQL).BuildInsertStruct("user", []*structUser{{Name: "deen", Version: 1}, {Name: "dingding", Age: &age, Version: 2}})
+	ass.NoError(err)
+	ass.Equal("INSERT INTO user (age,name,version) VALUES ($1,$2,$3),($4,$5,$6)", cond)
+	ass.Equal([]interface{}{nil, "deen", 1, 20, "dingding", 2}, vals)
+
+	_, _, err = BuildInsertStruct("user", []structUser{{Name: "deen", Version: 1}, {Name: "dingding"}})
+	ass.Equal(errStructColumnsNotMatch, err)
+	// the same number of columns but different ones
+	_, _, err = BuildInsertStruct("user", []structUser{{Name: "deen", Version: 1}, {Name: "dingding", Nickname: &nickname}})
+	ass.Equal(errStructColumnsNotMatch, err)
+	_, _, err = BuildInsertStruct("user", []structUser{})
+	ass.Equal(errInsertNullData, err)
+	_, _, err = BuildInsertStruct("user", 1)
+	ass.Equal(scanner.ErrNoneStructTarget, err)
+}
+
+func TestBuildUpdateStruct(t *testing.T) {
+	ass := assert.New(t)
+	cond, vals, err := BuildUpdateStruct("user", structUser{ID: 3, Name: "deen", CreatedAt: time.Now()})
+	ass.NoError(err)
+	ass.Equal("UPDATE user SET age=?,name=? WHERE (id=?)", cond)
+	ass.Equal([]interface{}{nil, "deen", int64(3)}, vals)
+
+	nickname := "dd"
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).BuildUpdateStruct("user", &structUser{ID: 3, Name: "deen", Nickname: &nickname, Version: 2})
+	ass.NoError(err)
+	ass.Equal(`UPDATE "user" SET "age"=$1,"name"=$2,"nickname"=$3,"version"=$4 WHERE ("id"=$5)`, cond)
+	ass.Equal([]interface{}{nil, "deen", "dd", 2, int64(3)}, vals)
+
+	_, _, err = BuildUpdateStruct("user", structUser{Name: "deen"})
+	ass.Equal(errStructNoPrimaryKey, err)
+	_, _, err = BuildUpdateStruct("user", struct {
+		Name string `ddb:"name"`
+	}{"deen"})
+	ass.Equal(errStructNoPrimaryKey, err)
+	_, _, err = BuildUpdateStruct("user", struct {
+		ID   int    `ddb:"id,pk"`
+		Name string `ddb:"name,omitempty"`
+	}{ID: 1})
+	ass.Equal(errStructNothingToUpdate, err)
+	_, _, err = BuildUpdateStruct("user", []structUser{})
+	ass.Equal(scanner.ErrNoneStructTarget, err)
+}
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errSubqueryAlias       = errors.New("[builder] the alias of a derived table can't be empty")
+	errSubqueryPlaceholder = errors.New("[builder] the statement of a subquery must use ? placeholders, build it by Builder.Subquery or SelectBuilder.Subquery for other dialects")
+)
+
+// Subquery is a SELECT statement used inside another statement.
+// It could be the value of where keys like "id in", "age >" and "_exists",
+// or a derived table of BuildSelectFrom.
+type Subquery struct {
+	cond string
+	vals []interface{}
+}
+
+// NewSubquery returns the Subquery of a statement built by the package-level
+// BuildSelect, so that it can be called as NewSubquery(BuildSelect(table, where, fields)).
+// The statement must use ? placeholders, the outer statement replaces them
+// in the way of its dialect. A statement with numbered placeholders like $1,
+// such as the one of New(PostgreSQL).BuildSelect, is rejected, since its
+// placeholders would bind to the args of the outer statement.
+func NewSubquery(cond string, vals []interface{}, err error) (*Subquery, error) {
+	if nil != err {
+		return nil, err
+	}
+	if hasNumberedPlaceholder(cond) {
+		return nil, errSubqueryPlaceholder
+	}
+	return &Subquery{cond: cond, vals: vals}, nil
+}
+
+// hasNumberedPlaceholder reports whether sql has a $n placeholder outside of quotes
+func hasNumberedPlaceholder(sql string) bool {
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			return true
+		}
+	}
+	return false
+}
+
+// Subquery builds a SELECT statement just like BuildSelect and returns it as a Subquery
+func (b *Builder) Subquery(table string, where map[string]interface{}, selectField []string) (*Subquery, error) {
+	return NewSubquery(b.buildSelectMap(table, where, selectField))
+}
+
+// Build returns the statement and its arguments, with ? placeholders
+func (s *Subquery) Build() (string, []interface{}) {
+	return s.cond, s.vals
+}
+
+func (s *Subquery) expression() (string, []interface{}) {
+	return "(" + s.cond + ")", s.vals
+}
+
+type existsComparable struct {
+	subs []*Subquery
+	not  bool
+}
+
+func (e existsComparable) Build() ([]string, []interface{}) {
+	var cond []string
+	var vals []interface{}
+	operator := "EXISTS "
+	if e.not {
+		operator = "NOT EXISTS "
+	}
+	for _, sub := range e.subs {
+		if nil == sub {
+			continue
+		}
+		subString, subVals := sub.expression()
+		cond = append(cond, operator+subString)
+		vals = append(vals, subVals...)
+	}
+	return cond, vals
+}
+
+// BuildSelectFrom is the same as BuildSelect but selects from a derived table
+func BuildSelectFrom(from *Subquery, alias string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	return defaultBuilder.BuildSelectFrom(from, alias, where, selectField)
+}
+
+// BuildSelectFrom is the same as the package-level BuildSelectFrom but in the dialect of b
+func (b *Builder) BuildSelectFrom(from *Subquery, alias string, where map[string]interface{}, selectField []string) (string, []interface{}, error) {
+	if nil == from {
+		return "", nil, errNilSubquery
+	}
+	if "" == strings.TrimSpace(alias) {
+		return "", nil, errSubqueryAlias
+	}
+	stmt, err := b.resolveSelect(alias, where, selectField)
+	if nil != err {
+		return "", nil, err
+	}
+	stmt.from = from
+	return b.rebind(b.buildSelect(stmt))
+}
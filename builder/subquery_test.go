@@ -0,0 +1,94 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubqueryInWhere(t *testing.T) {
+	ass := assert.New(t)
+	paid, err := NewSubquery(BuildSelect("orders", map[string]interface{}{"status": "paid", "price >": 100}, []string{"uid"}))
+	ass.NoError(err)
+	avg, err := NewSubquery(BuildSelect("user", map[string]interface{}{"city": "Beijing"}, []string{"avg(age)"}))
+	ass.NoError(err)
+	banned, err := New(MySQL).Subquery("ban", map[string]interface{}{"ban.uid": Col("u.id"), "ban.level >=": 3}, []string{"1"})
+	ass.NoError(err)
+
+	where := map[string]interface{}{
+		"u.name":         "deen",
+		"u.id in":        paid,
+		"u.id not in":    []int{1, 2},
+		"u.age >":        avg,
+		"_not_exists":    banned,
+		"u.group not in": paid,
+	}
+	cond, vals, err := BuildSelect("user u", where, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM user u WHERE (u.name=? AND u.id NOT IN (?,?) AND u.age>(SELECT avg(age) FROM user WHERE (city=?)) AND "+
+		"u.id IN (SELECT uid FROM orders WHERE (status=? AND price>?)) AND u.group NOT IN (SELECT uid FROM orders WHERE (status=? AND price>?)) AND "+
+		"NOT EXISTS (SELECT 1 FROM ban WHERE (ban.uid=u.id AND ban.level>=?)))", cond)
+	ass.Equal([]interface{}{"deen", 1, 2, "Beijing", "paid", 100, "paid", 100, 3}, vals)
+
+	cond, vals, err = New(PostgreSQL).BuildDelete("user", map[string]interface{}{
+		"age >":   18,
+		"_exists": []*Subquery{paid, banned},
+	})
+	ass.NoError(err)
+	ass.Equal("DELETE FROM user WHERE (age>$1 AND EXISTS (SELECT uid FROM orders WHERE (status=$2 AND price>$3)) AND "+
+		"EXISTS (SELECT 1 FROM ban WHERE (ban.uid=u.id AND ban.level>=$4)))", cond)
+	ass.Equal([]interface{}{18, "paid", 100, 3}, vals)
+
+	var nilSub *Subquery
+	_, _, err = BuildSelect("user", map[string]interface{}{"id in": nilSub}, nil)
+	ass.Equal(errNilSubquery, err)
+	_, _, err = BuildSelect("user", map[string]interface{}{"_exists": "select 1"}, nil)
+	ass.Equal(errExistsValueType, err)
+	_, _, err = BuildSelect("user", map[string]interface{}{"id in subquery": []int{1}}, nil)
+	ass.Equal(ErrUnsupportedOperator, err)
+	_, err = NewSubquery(BuildSelect("user", map[string]interface{}{"_limit": 1}, nil))
+	ass.Equal(errLimitValueType, err)
+
+	pg := New(PostgreSQL)
+	_, err = NewSubquery(pg.BuildSelect("orders", map[string]interface{}{"x": 1}, []string{"uid"}))
+	ass.Equal(errSubqueryPlaceholder, err, "$1 would bind to the args of the outer statement")
+	sub, err := pg.Subquery("orders", map[string]interface{}{"x": 1}, []string{"uid"})
+	ass.NoError(err)
+	cond, vals, err = pg.BuildSelect("user", map[string]interface{}{"a": 5, "id in": sub}, nil)
+	ass.NoError(err)
+	ass.Equal("SELECT * FROM user WHERE (a=$1 AND id IN (SELECT uid FROM orders WHERE (x=$2)))", cond)
+	ass.Equal([]interface{}{5, 1}, vals)
+	_, err = NewSubquery("SELECT uid FROM orders WHERE note='$1' AND price>?", []interface{}{1}, nil)
+	ass.NoError(err, "quoted $1 isn't a placeholder")
+}
+
+func TestBuildSelectFrom(t *testing.T) {
+	ass := assert.New(t)
+	from, err := NewSubquery(BuildSelect("orders", map[string]interface{}{
+		"status":   "paid",
+		"_groupby": "uid",
+	}, []string{"uid", "sum(price) as total"}))
+	ass.NoError(err)
+	cond, vals, err := BuildSelectFrom(from, "t", map[string]interface{}{
+		"total >":  100,
+		"_orderby": "total desc",
+		"_limit":   []uint{10},
+	}, []string{"uid", "total"})
+	ass.NoError(err)
+	ass.Equal("SELECT uid,total FROM (SELECT uid,sum(price) as total FROM orders WHERE (status=?) GROUP BY uid) AS t WHERE (total>?) ORDER BY total desc LIMIT ?,?", cond)
+	ass.Equal([]interface{}{"paid", 100, 0, 10}, vals)
+
+	cond, vals, err = New(PostgreSQL).Quote(QuoteAll).BuildSelectFrom(from, "t", map[string]interface{}{
+		"_join":     []Join{{Type: InnerJoin, Table: "user u", On: map[string]interface{}{"u.id": Col("t.uid"), "u.age >": 18}}},
+		"t.total >": 100,
+	}, []string{"u.name", "t.total"})
+	ass.NoError(err)
+	ass.Equal(`SELECT "u"."name","t"."total" FROM (SELECT uid,sum(price) as total FROM orders WHERE (status=$1) GROUP BY uid) AS "t" `+
+		`INNER JOIN "user" AS "u" ON ("u"."id"="t"."uid" AND "u"."age">$2) WHERE ("t"."total">$3)`, cond)
+	ass.Equal([]interface{}{"paid", 18, 100}, vals)
+
+	_, _, err = BuildSelectFrom(nil, "t", nil, nil)
+	ass.Equal(errNilSubquery, err)
+	_, _, err = BuildSelectFrom(from, " ", nil, nil)
+	ass.Equal(errSubqueryAlias, err)
+}
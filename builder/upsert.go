@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	errUpsertAction    = errors.New("[builder] unknown UpsertAction")
+	errUpsertNotInsert = errors.New("[builder] Overwrite and Add only apply to the inserted columns")
+	errUpsertAddAlias  = errors.New("[builder] Add refers to the column by the table, which can't have an alias")
+)
+
+// UpsertAction tells BuildUpsert what to do with a column of the existing row
+// when the inserted row conflicts with it
+type UpsertAction int
+
+const (
+	_ UpsertAction = iota
+	// Overwrite sets the column to the value of the inserted row
+	Overwrite
+	// Keep leaves the column as it is
+	Keep
+	// Add adds the value of the inserted row to the column, the table can't have an alias
+	Add
+)
+
+// BuildUpsert inserts data, and updates the existing rows conflicting with
+// the inserted ones. conflict is the list of the conflict target columns,
+// PostgreSQL requires it while MySQL ignores it.
+// The value of each column in update is an UpsertAction, a Raw expression or
+// a value set to the column, use Dialect().Inserted in the expression to refer
+// to the inserted value. The inserted columns missing in update and not listed in conflict are overwritten,
+// if nothing is to be updated the conflicting rows are left as they are.
+func BuildUpsert(table string, data []map[string]interface{}, conflict []string, update map[string]interface{}) (string, []interface{}, error) {
+	return defaultBuilder.BuildUpsert(table, data, conflict, update)
+}
+
+// BuildUpsert is the same as the package-level BuildUpsert but in the dialect of b
+func (b *Builder) BuildUpsert(table string, data []map[string]interface{}, conflict []string, update map[string]interface{}) (string, []interface{}, error) {
+	return b.rebind(b.buildUpsert(table, data, conflict, update))
+}
+
+func (b *Builder) buildUpsert(table string, data []map[string]interface{}, conflict []string, update map[string]interface{}) (string, []interface{}, error) {
+	insertCond, vals, err := b.buildInsert(table, data, commonInsert)
+	if nil != err {
+		return "", nil, err
+	}
+	aliased := strings.ContainsAny(strings.TrimSpace(table), " \t\n")
+	if table, err = b.quoteIdent(table); nil != err {
+		return "", nil, err
+	}
+	quotedConflict := make([]string, len(conflict))
+	for i, column := range conflict {
+		if quotedConflict[i], err = b.quoteIdent(column); nil != err {
+			return "", nil, err
+		}
+	}
+	actions := make(map[string]interface{}, len(update))
+	for k, v := range update {
+		actions[k] = v
+	}
+	inserted := make(map[string]bool, len(data[0]))
+	for _, field := range resolveFields(data[0]) {
+		inserted[field] = true
+		if _, ok := actions[field]; !ok && !isStringInSlice(field, conflict) {
+			actions[field] = Overwrite
+		}
+	}
+	columns := make([]string, 0, len(actions))
+	for column := range actions {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	var sets []string
+	for _, column := range columns {
+		field, err := b.quoteIdent(column)
+		if nil != err {
+			return "", nil, err
+		}
+		switch action := actions[column].(type) {
+		case UpsertAction:
+			if action != Overwrite && action != Add && action != Keep {
+				return "", nil, errUpsertAction
+			}
+			if Keep == action {
+				continue
+			}
+			if !inserted[column] {
+				return "", nil, errUpsertNotInsert
+			}
+			if Add == action && aliased {
+				return "", nil, errUpsertAddAlias
+			}
+			if Overwrite == action {
+				sets = append(sets, fmt.Sprintf("%s=%s", field, b.dialect.Inserted(field)))
+			} else {
+				sets = append(sets, fmt.Sprintf("%s=%s.%s+%s", field, table, field, b.dialect.Inserted(field)))
+			}
+		case expression:
+			exprString, exprVals := action.expression()
+			sets = append(sets, field+"="+exprString)
+			vals = append(vals, exprVals...)
+		default:
+			sets = append(sets, field+"=?")
+			vals = append(vals, action)
+		}
+	}
+	clause, err := b.dialect.Upsert(quotedConflict, strings.Join(sets, ","))
+	if nil != err {
+		return "", nil, err
+	}
+	return insertCond + clause, vals, nil
+}
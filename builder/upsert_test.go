@@ -0,0 +1,105 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildUpsert(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "name": "deen", "total": 10, "created_at": "2020-01-01"},
+		{"id": 2, "name": "dingding", "total": 5, "created_at": "2020-01-02"},
+	}
+	update := map[string]interface{}{
+		"total":      Add,
+		"created_at": Keep,
+		"score":      Expr("score+?", 1),
+		"status":     "active",
+	}
+	var data2 = []struct {
+		dialect  Dialect
+		conflict []string
+		update   map[string]interface{}
+		cond     string
+		vals     []interface{}
+	}{
+		{
+			dialect:  MySQL,
+			conflict: []string{"id"},
+			update:   update,
+			cond: "INSERT INTO tb (created_at,id,name,total) VALUES (?,?,?,?),(?,?,?,?) ON DUPLICATE KEY UPDATE " +
+				"name=VALUES(name),score=score+?,status=?,total=tb.total+VALUES(total)",
+			vals: []interface{}{"2020-01-01", 1, "deen", 10, "2020-01-02", 2, "dingding", 5, 1, "active"},
+		},
+		{
+			dialect:  PostgreSQL,
+			conflict: []string{"id"},
+			update:   update,
+			cond: "INSERT INTO tb (created_at,id,name,total) VALUES ($1,$2,$3,$4),($5,$6,$7,$8) ON CONFLICT (id) DO UPDATE SET " +
+				"name=EXCLUDED.name,score=score+$9,status=$10,total=tb.total+EXCLUDED.total",
+			vals: []interface{}{"2020-01-01", 1, "deen", 10, "2020-01-02", 2, "dingding", 5, 1, "active"},
+		},
+		{
+			dialect:  SQLite,
+			conflict: []string{"id"},
+			update:   nil,
+			cond: "INSERT INTO tb (created_at,id,name,total) VALUES (?,?,?,?),(?,?,?,?) ON CONFLICT (id) DO UPDATE SET " +
+				"created_at=excluded.created_at,name=excluded.name,total=excluded.total",
+			vals: []interface{}{"2020-01-01", 1, "deen", 10, "2020-01-02", 2, "dingding", 5},
+		},
+		{
+			dialect:  PostgreSQL,
+			conflict: []string{"id"},
+			update:   map[string]interface{}{"name": Keep, "total": Keep, "created_at": Keep},
+			cond:     "INSERT INTO tb (created_at,id,name,total) VALUES ($1,$2,$3,$4),($5,$6,$7,$8) ON CONFLICT (id) DO NOTHING",
+			vals:     []interface{}{"2020-01-01", 1, "deen", 10, "2020-01-02", 2, "dingding", 5},
+		},
+		{
+			dialect:  MySQL,
+			conflict: []string{"id"},
+			update:   map[string]interface{}{"name": Keep, "total": Keep, "created_at": Keep},
+			cond:     "INSERT INTO tb (created_at,id,name,total) VALUES (?,?,?,?),(?,?,?,?) ON DUPLICATE KEY UPDATE id=id",
+			vals:     []interface{}{"2020-01-01", 1, "deen", 10, "2020-01-02", 2, "dingding", 5},
+		},
+	}
+	ass := assert.New(t)
+	for _, tc := range data2 {
+		cond, vals, err := New(tc.dialect).BuildUpsert("tb", data, tc.conflict, tc.update)
+		ass.NoError(err)
+		ass.Equal(tc.cond, cond)
+		ass.Equal(tc.vals, vals)
+	}
+	ass.Len(update, 4, "update shouldn't be modified")
+}
+
+func TestBuildUpsertQuote(t *testing.T) {
+	ass := assert.New(t)
+	data := []map[string]interface{}{{"key": 1, "order": 2}}
+	cond, vals, err := New(PostgreSQL).Quote(QuoteAll).BuildUpsert("user", data, []string{"key"}, map[string]interface{}{"order": Add})
+	ass.NoError(err)
+	ass.Equal(`INSERT INTO "user" ("key","order") VALUES ($1,$2) ON CONFLICT ("key") DO UPDATE SET "order"="user"."order"+EXCLUDED."order"`, cond)
+	ass.Equal([]interface{}{1, 2}, vals)
+
+	cond, _, err = New(MySQL).Quote(QuoteAll).BuildUpsert("user", data, []string{"key"}, nil)
+	ass.NoError(err)
+	ass.Equal("INSERT INTO `user` (`key`,`order`) VALUES (?,?) ON DUPLICATE KEY UPDATE `order`=VALUES(`order`)", cond)
+}
+
+func TestBuildUpsertError(t *testing.T) {
+	ass := assert.New(t)
+	data := []map[string]interface{}{{"id": 1, "name": "deen"}}
+	_, _, err := BuildUpsert("tb", nil, []string{"id"}, nil)
+	ass.Equal(errInsertNullData, err)
+	_, _, err = BuildUpsert("tb", data, []string{"id"}, map[string]interface{}{"age": Add})
+	ass.Equal(errUpsertNotInsert, err)
+	_, _, err = BuildUpsert("tb t", []map[string]interface{}{{"id": 1, "n": 2}}, []string{"id"}, map[string]interface{}{"n": Add})
+	ass.Equal(errUpsertAddAlias, err, "tb t.n isn't a column")
+	_, _, err = BuildUpsert("tb", data, []string{"id"}, map[string]interface{}{"name": UpsertAction(100)})
+	ass.Equal(errUpsertAction, err)
+	_, _, err = BuildUpsert("tb", data, nil, map[string]interface{}{"id": Keep, "name": Keep})
+	ass.True(errors.Is(err, ErrUnsupportedSyntax))
+	_, _, err = New(PostgreSQL).BuildUpsert("tb", data, nil, nil)
+	ass.True(errors.Is(err, ErrUnsupportedSyntax))
+}
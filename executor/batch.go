@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/didi/gendry/builder"
+)
+
+// ExecBatches executes the statements of builder.BuildInsertBatches in a transaction by WithTx,
+// and returns the total number of affected rows. If any of them fails, the changes are rolled back.
+// db is the same as the one of WithTx, so the batches could be a part of an outer transaction.
+func ExecBatches(ctx context.Context, db Querier, stmts []builder.Statement) (int64, error) {
+	var total int64
+	err := WithTx(ctx, db, nil, func(tx *Tx) error {
+		total = 0
+		for _, stmt := range stmts {
+			affected, err := exec(ctx, tx, stmt.SQL, stmt.Args)
+			if nil != err {
+				return err
+			}
+			total += affected
+		}
+		return nil
+	})
+	if nil != err {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/didi/gendry/builder"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecBatches(t *testing.T) {
+	should := require.New(t)
+	data := []map[string]interface{}{{"id": 0, "name": "deen"}, {"id": 1, "name": "deen"}, {"id": 2, "name": "deen"}}
+	stmts, err := builder.BuildInsertBatches("tb", data, builder.BatchLimit{Rows: 2})
+	should.NoError(err)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tb").WithArgs(0, "deen", 1, "deen").WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectExec("INSERT INTO tb").WithArgs(2, "deen").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+	affected, err := ExecBatches(ctx, db, stmts)
+	should.NoError(err)
+	should.Equal(int64(3), affected)
+
+	execErr := errors.New("packet too large")
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tb").WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectExec("INSERT INTO tb").WillReturnError(execErr)
+	mock.ExpectRollback()
+	_, err = ExecBatches(ctx, db, stmts)
+	should.Equal(execErr, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO tb").WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectExec("INSERT INTO tb").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	tx, err := db.BeginTx(ctx, nil)
+	should.NoError(err)
+	_, err = tx.ExecContext(ctx, "UPDATE user SET age=1")
+	should.NoError(err)
+	affected, err = ExecBatches(ctx, tx, stmts)
+	should.NoError(err)
+	should.Equal(int64(3), affected, "inside the transaction of the caller")
+	should.NoError(tx.Commit())
+	should.NoError(mock.ExpectationsWereMet())
+}
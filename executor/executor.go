@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/didi/gendry/builder"
+	"github.com/didi/gendry/scanner"
+)
+
+// Querier is the interface satisfied by *sql.DB, *sql.Tx and *sql.Conn,
+// so that the helpers work both inside and outside transactions
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Executor builds statements with a Builder and executes them.
+// The package-level functions use a MySQL Executor.
+type Executor struct {
+	builder *builder.Builder
+}
+
+var defaultExecutor = New(nil)
+
+// New returns an Executor building statements with b,
+// a nil b means the MySQL builder
+func New(b *builder.Builder) *Executor {
+	if nil == b {
+		b = builder.New(builder.MySQL)
+	}
+	return &Executor{builder: b}
+}
+
+// Builder returns the Builder of the Executor
+func (e *Executor) Builder() *builder.Builder {
+	return e.builder
+}
+
+// Select queries the table with where and fields, and scans the rows into dest.
+// dest is the same as the target of scanner.Scan, when it's not a pointer of slice
+// scanner.ErrEmptyResult is returned if no rows match.
+// The special keys of where such as _orderby and _limit work as in builder.BuildSelect.
+func Select(ctx context.Context, db Querier, table string, where map[string]interface{}, fields []string, dest interface{}) error {
+	return defaultExecutor.Select(ctx, db, table, where, fields, dest)
+}
+
+// Select is the same as the package-level Select but in the dialect of e
+func (e *Executor) Select(ctx context.Context, db Querier, table string, where map[string]interface{}, fields []string, dest interface{}) error {
+	cond, vals, err := e.builder.BuildSelect(table, where, fields)
+	if nil != err {
+		return err
+	}
+	return Query(ctx, db, cond, vals, dest)
+}
+
+// SelectPage is the same as Select, besides it returns the total number of rows matching where,
+// which is counted by builder.BuildCount with the same where and fields, ignoring _orderby,
+// _limit, _lockMode and _keyset.
+// The page and the total are queried one after another, run SelectPage in a transaction
+// for a consistent result.
+func SelectPage(ctx context.Context, db Querier, table string, where map[string]interface{}, fields []string, dest interface{}) (total int64, err error) {
+	return defaultExecutor.SelectPage(ctx, db, table, where, fields, dest)
+}
+
+// SelectPage is the same as the package-level SelectPage but in the dialect of e
+func (e *Executor) SelectPage(ctx context.Context, db Querier, table string, where map[string]interface{}, fields []string, dest interface{}) (total int64, err error) {
+	cond, vals, err := e.builder.BuildCount(table, where, fields)
+	if nil != err {
+		return 0, err
+	}
+	if err = e.Select(ctx, db, table, where, fields, dest); nil != err {
+		return 0, err
+	}
+	if err = Query(ctx, db, cond, vals, &total); nil != err {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Query executes a query built elsewhere and scans the rows into dest
+func Query(ctx context.Context, db Querier, query string, args []interface{}, dest interface{}) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if nil != err {
+		return err
+	}
+	return scanner.ScanClose(rows, dest)
+}
+
+// Insert inserts data into the table, and returns the last insert id and the number of affected rows.
+// The last insert id is 0 if the driver doesn't support it, such as lib/pq.
+func Insert(ctx context.Context, db Querier, table string, data []map[string]interface{}) (lastInsertID, affected int64, err error) {
+	return defaultExecutor.Insert(ctx, db, table, data)
+}
+
+// Insert is the same as the package-level Insert but in the dialect of e
+func (e *Executor) Insert(ctx context.Context, db Querier, table string, data []map[string]interface{}) (lastInsertID, affected int64, err error) {
+	cond, vals, err := e.builder.BuildInsert(table, data)
+	if nil != err {
+		return 0, 0, err
+	}
+	result, err := db.ExecContext(ctx, cond, vals...)
+	if nil != err {
+		return 0, 0, err
+	}
+	// drivers without LastInsertId return an error, which isn't an error of the insert
+	lastInsertID, _ = result.LastInsertId()
+	affected, err = result.RowsAffected()
+	return lastInsertID, affected, err
+}
+
+// Update updates the rows of the table matching where, and returns the number of affected rows
+func Update(ctx context.Context, db Querier, table string, where, update map[string]interface{}) (int64, error) {
+	return defaultExecutor.Update(ctx, db, table, where, update)
+}
+
+// Update is the same as the package-level Update but in the dialect of e
+func (e *Executor) Update(ctx context.Context, db Querier, table string, where, update map[string]interface{}) (int64, error) {
+	cond, vals, err := e.builder.BuildUpdate(table, where, update)
+	if nil != err {
+		return 0, err
+	}
+	return exec(ctx, db, cond, vals)
+}
+
+// Delete deletes the rows of the table matching where, and returns the number of affected rows
+func Delete(ctx context.Context, db Querier, table string, where map[string]interface{}) (int64, error) {
+	return defaultExecutor.Delete(ctx, db, table, where)
+}
+
+// Delete is the same as the package-level Delete but in the dialect of e
+func (e *Executor) Delete(ctx context.Context, db Querier, table string, where map[string]interface{}) (int64, error) {
+	cond, vals, err := e.builder.BuildDelete(table, where)
+	if nil != err {
+		return 0, err
+	}
+	return exec(ctx, db, cond, vals)
+}
+
+func exec(ctx context.Context, db Querier, query string, args []interface{}) (int64, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if nil != err {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,187 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/didi/gendry/builder"
+	"github.com/didi/gendry/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   int64  `ddb:"id"`
+	Name string `ddb:"name"`
+}
+
+func TestSelect(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id,name FROM user WHERE (age>?) ORDER BY id LIMIT ?,?")).
+		WithArgs(18, 0, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "deen").AddRow(2, "caibirdme"))
+	var users []user
+	should.NoError(Select(ctx, db, "user", map[string]interface{}{"age >": 18, "_orderby": "id", "_limit": []uint{0, 10}}, []string{"id", "name"}, &users))
+	should.Equal([]user{{1, "deen"}, {2, "caibirdme"}}, users)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id,name FROM user WHERE (id=?)")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	var u user
+	should.Equal(scanner.ErrEmptyResult, Select(ctx, db, "user", map[string]interface{}{"id": 3}, []string{"id", "name"}, &u))
+
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("gone away"))
+	should.EqualError(Select(ctx, db, "user", nil, nil, &users), "gone away")
+
+	_, _, err = builder.BuildSelect("user", map[string]interface{}{"_limit": "x"}, nil)
+	should.Equal(err, Select(ctx, db, "user", map[string]interface{}{"_limit": "x"}, nil, &users))
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestSelectPage(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+	where := map[string]interface{}{"age >": 18, "_orderby": "id", "_limit": []uint{10, 2}}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id,name FROM user WHERE (age>?) ORDER BY id LIMIT ?,?")).
+		WithArgs(18, 10, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(11, "deen").AddRow(12, "caibirdme"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM user WHERE (age>?)")).
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(42))
+	var users []user
+	total, err := SelectPage(ctx, db, "user", where, []string{"id", "name"}, &users)
+	should.NoError(err)
+	should.Equal(int64(42), total)
+	should.Equal([]user{{11, "deen"}, {12, "caibirdme"}}, users)
+
+	grouped := map[string]interface{}{"_groupby": "name", "_having": map[string]interface{}{"id >": 1}}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT max(id) AS id,name FROM user GROUP BY name HAVING (id>?)")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(11, "deen"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM (SELECT max(id) AS id,name FROM user GROUP BY name HAVING (id>?)) AS t")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	total, err = SelectPage(ctx, db, "user", grouped, []string{"max(id) AS id", "name"}, &users)
+	should.NoError(err)
+	should.Equal(int64(1), total)
+
+	mock.ExpectQuery("SELECT id,name").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery("SELECT count").WillReturnError(errors.New("gone away"))
+	_, err = SelectPage(ctx, db, "user", where, []string{"id", "name"}, &users)
+	should.EqualError(err, "gone away")
+
+	_, err = SelectPage(ctx, db, "user", map[string]interface{}{"_groupby": 1}, nil, &users)
+	should.Error(err)
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestExec(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user (id,name) VALUES (?,?),(?,?)")).
+		WithArgs(1, "deen", 2, "caibirdme").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	id, affected, err := Insert(ctx, db, "user", []map[string]interface{}{{"id": 1, "name": "deen"}, {"id": 2, "name": "caibirdme"}})
+	should.NoError(err)
+	should.Equal(int64(2), id)
+	should.Equal(int64(2), affected)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE user SET name=? WHERE (id=?)")).
+		WithArgs("deen", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	affected, err = Update(ctx, db, "user", map[string]interface{}{"id": 1}, map[string]interface{}{"name": "deen"})
+	should.NoError(err)
+	should.Equal(int64(1), affected)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user WHERE (id IN (?,?))")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	affected, err = Delete(ctx, db, "user", map[string]interface{}{"id in": []interface{}{1, 2}})
+	should.NoError(err)
+	should.Equal(int64(2), affected)
+
+	mock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewErrorResult(errors.New("no rows affected")))
+	_, err = Delete(ctx, db, "user", map[string]interface{}{"id": 1})
+	should.EqualError(err, "no rows affected")
+
+	_, _, err = Insert(ctx, db, "user", nil)
+	should.Error(err)
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestQuerier(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE user SET name=? WHERE (id=?)")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	tx, err := db.Begin()
+	should.NoError(err)
+	_, err = Update(ctx, tx, "user", map[string]interface{}{"id": 1}, map[string]interface{}{"name": "deen"})
+	should.NoError(err)
+	should.NoError(tx.Commit())
+
+	conn, err := db.Conn(ctx)
+	should.NoError(err)
+	defer conn.Close()
+	var _ Querier = conn
+	var _ Querier = (*sql.DB)(nil)
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+// noLastInsertID is the result of drivers such as lib/pq
+type noLastInsertID int64
+
+func (noLastInsertID) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported")
+}
+
+func (r noLastInsertID) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+func TestExecutorDialect(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	e := New(builder.New(builder.PostgreSQL))
+	should.Equal(builder.PostgreSQL, e.Builder().Dialect())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id,name FROM user WHERE (id=$1 AND name=$2)")).
+		WithArgs(1, "deen").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "deen"))
+	var u *user
+	should.NoError(e.Select(ctx, db, "user", map[string]interface{}{"id": 1, "name": "deen"}, []string{"id", "name"}, &u))
+	should.Equal(&user{1, "deen"}, u)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user (id) VALUES ($1)")).
+		WithArgs(1).
+		WillReturnResult(noLastInsertID(1))
+	id, affected, err := e.Insert(ctx, db, "user", []map[string]interface{}{{"id": 1}})
+	should.NoError(err)
+	should.Equal(int64(0), id)
+	should.Equal(int64(1), affected)
+	should.NoError(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ErrNotBeginner means the db of WithTx can neither begin a transaction nor create a savepoint
+var ErrNotBeginner = errors.New("[executor]: db must be a *sql.DB, *sql.Conn, *sql.Tx or *Tx")
+
+const (
+	// MySQLDeadlock is the MySQL error number of deadlocks
+	MySQLDeadlock = 1213
+	// MySQLLockWaitTimeout is the MySQL error number of lock wait timeouts
+	MySQLLockWaitTimeout = 1205
+)
+
+// Tx is the transaction passed to the function of WithTx.
+// Don't Commit or Rollback it, WithTx does that according to the result of the function.
+type Tx struct {
+	*sql.Tx
+	// depth is the number of savepoints the Tx is inside
+	depth int
+}
+
+// TxOptions are the options of WithTx
+type TxOptions struct {
+	// Isolation and ReadOnly are passed to BeginTx
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// MaxRetries is how many times the transaction is retried after a retryable error,
+	// 0 means no retries
+	MaxRetries int
+	// Backoff returns the delay before the n-th(starting from 1) retry, nil means no delay
+	Backoff func(n int) time.Duration
+	// Retryable reports whether the transaction should be retried after err,
+	// nil means IsDeadlock
+	Retryable func(err error) bool
+}
+
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithTx runs fn in a transaction, which is committed if fn returns nil and rolled back otherwise.
+// If fn panics the transaction is rolled back and the panic goes on.
+// A failed transaction is retried if opts allows, see TxOptions.
+//
+// db may be a *sql.DB or a *sql.Conn, where a transaction begins. It may also be a *sql.Tx
+// or the *Tx of an outer WithTx, where a SAVEPOINT is created instead, and the changes of fn
+// are rolled back to it if fn fails, without affecting the outer transaction.
+// opts are ignored for savepoints, the outermost WithTx is the one to retry.
+func WithTx(ctx context.Context, db Querier, opts *TxOptions, fn func(tx *Tx) error) error {
+	switch t := db.(type) {
+	case *Tx:
+		return withSavepoint(ctx, t, fn)
+	case *sql.Tx:
+		return withSavepoint(ctx, &Tx{Tx: t}, fn)
+	case beginner:
+		if nil == opts {
+			opts = &TxOptions{}
+		}
+		return withRetries(ctx, t, opts, fn)
+	}
+	return ErrNotBeginner
+}
+
+func withRetries(ctx context.Context, db beginner, opts *TxOptions, fn func(tx *Tx) error) error {
+	retryable := opts.Retryable
+	if nil == retryable {
+		retryable = IsDeadlock
+	}
+	for n := 1; ; n++ {
+		err := withTx(ctx, db, opts, fn)
+		if nil == err || n > opts.MaxRetries || !retryable(err) {
+			return err
+		}
+		var delay time.Duration
+		if nil != opts.Backoff {
+			delay = opts.Backoff(n)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func withTx(ctx context.Context, db beginner, opts *TxOptions, fn func(tx *Tx) error) (err error) {
+	sqlTx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if nil != err {
+		return err
+	}
+	tx := &Tx{Tx: sqlTx}
+	defer func() {
+		if r := recover(); nil != r {
+			sqlTx.Rollback()
+			panic(r)
+		}
+	}()
+	if err = fn(tx); nil != err {
+		if errRollback := sqlTx.Rollback(); nil != errRollback {
+			return fmt.Errorf("%w, rollback failed: %v", err, errRollback)
+		}
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+func withSavepoint(ctx context.Context, outer *Tx, fn func(tx *Tx) error) (err error) {
+	tx := &Tx{Tx: outer.Tx, depth: outer.depth + 1}
+	name := "gendry_sp_" + strconv.Itoa(tx.depth)
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT "+name); nil != err {
+		return err
+	}
+	defer func() {
+		if r := recover(); nil != r {
+			tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+	if err = fn(tx); nil != err {
+		if _, errRollback := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); nil != errRollback {
+			return fmt.Errorf("%w, rollback to savepoint failed: %v", err, errRollback)
+		}
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// IsDeadlock reports whether err is a MySQL deadlock or lock wait timeout error,
+// which are worth retrying the transaction
+func IsDeadlock(err error) bool {
+	number, ok := MySQLErrorNumber(err)
+	return ok && (MySQLDeadlock == number || MySQLLockWaitTimeout == number)
+}
+
+var mysqlErrorPattern = regexp.MustCompile(`^Error (\d+)`)
+
+// MySQLErrorNumber returns the error number of a MySQL error, such as the Number of
+// *mysql.MySQLError, it looks into the wrapped errors.
+// It doesn't import the driver, so the number is read by the field name or the message.
+func MySQLErrorNumber(err error) (uint16, bool) {
+	for ; nil != err; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			if number := v.FieldByName("Number"); number.IsValid() && number.Kind() == reflect.Uint16 {
+				return uint16(number.Uint()), true
+			}
+		}
+		if m := mysqlErrorPattern.FindStringSubmatch(err.Error()); nil != m {
+			number, errParse := strconv.ParseUint(m[1], 10, 16)
+			return uint16(number), nil == errParse
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,232 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlError has the same fields as *mysql.MySQLError
+type mysqlError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *mysqlError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+var errDeadlock = &mysqlError{Number: MySQLDeadlock, Message: "Deadlock found when trying to get lock"}
+
+func TestWithTx(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	should.NoError(WithTx(ctx, db, nil, func(tx *Tx) error {
+		_, err := Update(ctx, tx, "user", map[string]interface{}{"id": 1}, map[string]interface{}{"name": "deen"})
+		return err
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	errFn := errors.New("failed")
+	should.Equal(errFn, WithTx(ctx, db, nil, func(tx *Tx) error {
+		return errFn
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	should.PanicsWithValue("boom", func() {
+		WithTx(ctx, db, nil, func(tx *Tx) error {
+			panic("boom")
+		})
+	})
+
+	mock.ExpectBegin().WillReturnError(errors.New("too many connections"))
+	should.EqualError(WithTx(ctx, db, nil, func(tx *Tx) error {
+		return nil
+	}), "too many connections")
+
+	should.Equal(ErrNotBeginner, WithTx(ctx, nil, nil, func(tx *Tx) error {
+		return nil
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectRollback().WillReturnError(errors.New("bad connection"))
+	err = WithTx(ctx, db, nil, func(tx *Tx) error {
+		return errDeadlock
+	})
+	should.EqualError(err, errDeadlock.Error()+", rollback failed: bad connection")
+	should.True(errors.Is(err, errDeadlock), "the error of fn is wrapped")
+	should.True(IsDeadlock(err))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT gendry_sp_1").WillReturnError(errors.New("bad connection"))
+	mock.ExpectRollback()
+	sqlTx, err := db.Begin()
+	should.NoError(err)
+	err = WithTx(ctx, sqlTx, nil, func(tx *Tx) error {
+		return errFn
+	})
+	should.True(errors.Is(err, errFn))
+	should.NoError(sqlTx.Rollback())
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWithTxRetry(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	var delays []int
+	opts := &TxOptions{
+		MaxRetries: 2,
+		Backoff: func(n int) time.Duration {
+			delays = append(delays, n)
+			return time.Millisecond
+		},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE").WillReturnError(errDeadlock)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("commit: %w", &mysqlError{Number: MySQLLockWaitTimeout}))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	calls := 0
+	should.NoError(WithTx(ctx, db, opts, func(tx *Tx) error {
+		calls++
+		_, err := tx.ExecContext(ctx, "UPDATE user SET age=age+1")
+		return err
+	}))
+	should.Equal(3, calls)
+	should.Equal([]int{1, 2}, delays)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	calls = 0
+	should.Equal(errDeadlock, WithTx(ctx, db, &TxOptions{MaxRetries: 1}, func(tx *Tx) error {
+		calls++
+		return errDeadlock
+	}))
+	should.Equal(2, calls, "retries are exhausted")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	errFn := errors.New("failed")
+	should.Equal(errFn, WithTx(ctx, db, &TxOptions{MaxRetries: 3}, func(tx *Tx) error {
+		return errFn
+	}), "not retryable")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	opts = &TxOptions{MaxRetries: 1, Retryable: func(err error) bool { return err == errFn }}
+	should.Equal(errFn, WithTx(ctx, db, opts, func(tx *Tx) error {
+		return errFn
+	}))
+
+	canceled, cancel := context.WithCancel(ctx)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	opts = &TxOptions{MaxRetries: 3, Backoff: func(int) time.Duration {
+		cancel()
+		return time.Hour
+	}}
+	should.Equal(errDeadlock, WithTx(canceled, db, opts, func(tx *Tx) error {
+		return errDeadlock
+	}), "stop retrying once the context is done")
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWithTxSavepoint(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	ctx := context.Background()
+
+	errFn := errors.New("failed")
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO user").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT gendry_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT gendry_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	should.NoError(WithTx(ctx, db, nil, func(tx *Tx) error {
+		if _, _, err := Insert(ctx, tx, "user", []map[string]interface{}{{"name": "deen"}}); nil != err {
+			return err
+		}
+		err := WithTx(ctx, tx, nil, func(tx *Tx) error {
+			Insert(ctx, tx, "log", []map[string]interface{}{{"msg": "x"}})
+			return errFn
+		})
+		should.Equal(errFn, err, "the outer transaction goes on")
+		return WithTx(ctx, tx, nil, func(tx *Tx) error {
+			return WithTx(ctx, tx, nil, func(tx *Tx) error {
+				return nil
+			})
+		})
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT gendry_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	sqlTx, err := db.Begin()
+	should.NoError(err)
+	should.Panics(func() {
+		WithTx(ctx, sqlTx, nil, func(tx *Tx) error {
+			panic("boom")
+		})
+	})
+	should.NoError(sqlTx.Rollback())
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestMySQLErrorNumber(t *testing.T) {
+	var data = []struct {
+		err    error
+		number uint16
+		ok     bool
+	}{
+		{errDeadlock, MySQLDeadlock, true},
+		{fmt.Errorf("update: %w", errDeadlock), MySQLDeadlock, true},
+		{errors.New("Error 1205: Lock wait timeout exceeded"), MySQLLockWaitTimeout, true},
+		{errors.New("Error 1062 (23000): Duplicate entry"), 1062, true},
+		{sql.ErrNoRows, 0, false},
+		{nil, 0, false},
+	}
+	should := require.New(t)
+	for _, tc := range data {
+		number, ok := MySQLErrorNumber(tc.err)
+		should.Equal(tc.number, number, "%v", tc.err)
+		should.Equal(tc.ok, ok, "%v", tc.err)
+	}
+	should.True(IsDeadlock(errDeadlock))
+	should.False(IsDeadlock(errors.New("Error 1062: Duplicate entry")))
+}
@@ -0,0 +1,267 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//Policy decides which healthy replica serves a read
+type Policy int
+
+const (
+	//RoundRobin takes turns among the healthy replicas, it's the default policy
+	RoundRobin Policy = iota
+	//Random picks a healthy replica at random
+	Random
+	//LeastLatency picks the healthy replica whose last health check was the fastest
+	LeastLatency
+)
+
+const defaultCheckTimeout = time.Second
+
+//ClusterOption stands for the options for creating a Cluster
+type ClusterOption struct {
+	primary      *Option
+	replicas     []*Option
+	policy       Policy
+	interval     time.Duration
+	checkTimeout time.Duration
+}
+
+//NewCluster returns a ClusterOption of a primary and its read replicas
+func NewCluster(primary *Option, replicas ...*Option) *ClusterOption {
+	return &ClusterOption{
+		primary:      primary,
+		replicas:     replicas,
+		checkTimeout: defaultCheckTimeout,
+	}
+}
+
+//Policy sets how reads are routed to the replicas, default RoundRobin
+func (c *ClusterOption) Policy(policy Policy) *ClusterOption {
+	c.policy = policy
+	return c
+}
+
+//HealthCheck pings the replicas every interval, a replica failing to respond
+//within timeout stops serving reads until it responds again.
+//Health checking is disabled by default.
+func (c *ClusterOption) HealthCheck(interval, timeout time.Duration) *ClusterOption {
+	c.interval = interval
+	if timeout > 0 {
+		c.checkTimeout = timeout
+	}
+	return c
+}
+
+//Open is used for creating a *Cluster
+//If ping=true, the primary must respond, while the replicas failing to respond
+//are marked unhealthy
+func (c *ClusterOption) Open(ping bool) (*Cluster, error) {
+	primary, err := c.primary.Open(ping)
+	if nil != err {
+		if nil != primary {
+			primary.Close()
+		}
+		return nil, err
+	}
+	replicas := make([]*sql.DB, 0, len(c.replicas))
+	for _, o := range c.replicas {
+		db, err := open(o)
+		if nil != err {
+			primary.Close()
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, err
+		}
+		replicas = append(replicas, db)
+	}
+	cluster := newCluster(primary, replicas, c)
+	if ping {
+		cluster.Check(context.Background())
+	}
+	cluster.start()
+	return cluster, nil
+}
+
+//Cluster routes writes, locking reads and transactions to the primary, and the other reads to the healthy replicas.
+//It falls back to the primary if no replica is healthy.
+//Cluster satisfies the Querier of the executor package.
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	option   ClusterOption
+	next     uint32
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	closed   sync.Once
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy int32
+	// latency is the duration of the last ping in nanoseconds
+	latency int64
+}
+
+func newCluster(primary *sql.DB, replicas []*sql.DB, option *ClusterOption) *Cluster {
+	c := &Cluster{
+		primary: primary,
+		option:  *option,
+		stop:    make(chan struct{}),
+	}
+	for _, db := range replicas {
+		c.replicas = append(c.replicas, &replica{db: db, healthy: 1})
+	}
+	return c
+}
+
+func (c *Cluster) start() {
+	if c.option.interval <= 0 || len(c.replicas) == 0 {
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.option.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.Check(context.Background())
+			}
+		}
+	}()
+}
+
+//Check pings all the replicas, and marks them healthy or not by the result
+func (c *Cluster) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.option.checkTimeout)
+			defer cancel()
+			begin := time.Now()
+			if nil != r.db.PingContext(ctx) {
+				atomic.StoreInt32(&r.healthy, 0)
+				return
+			}
+			atomic.StoreInt64(&r.latency, int64(time.Since(begin)))
+			atomic.StoreInt32(&r.healthy, 1)
+		}(r)
+	}
+	wg.Wait()
+}
+
+//Primary returns the *sql.DB of the primary
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+//Replica returns the *sql.DB of a healthy replica chosen by the policy,
+//or the primary if no replica is healthy
+func (c *Cluster) Replica() *sql.DB {
+	healthy := make([]*replica, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return c.primary
+	}
+	switch c.option.policy {
+	case Random:
+		return healthy[rand.Intn(len(healthy))].db
+	case LeastLatency:
+		fastest := healthy[0]
+		for _, r := range healthy[1:] {
+			if atomic.LoadInt64(&r.latency) < atomic.LoadInt64(&fastest.latency) {
+				fastest = r
+			}
+		}
+		return fastest.db
+	default:
+		n := atomic.AddUint32(&c.next, 1)
+		return healthy[(n-1)%uint32(len(healthy))].db
+	}
+}
+
+type primaryKey struct{}
+
+//UsePrimary returns a context which routes the reads of a Cluster to the primary,
+//use it for the reads which must see the latest writes, such as the ones after a write
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+var lockingClause = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|SHARE|NO\s+KEY\s+UPDATE|KEY\s+SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`)
+
+//isReplicaRead reports whether query could be served by a replica, only a SELECT
+//without a locking clause could, the others may write like INSERT ... RETURNING
+//or WITH ... DELETE, or lock rows which must be done on the primary
+func isReplicaRead(ctx context.Context, query string) bool {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return false
+	}
+	query = strings.TrimLeft(query, "( \t\r\n")
+	if len(query) < 6 || !strings.EqualFold(query[:6], "SELECT") {
+		return false
+	}
+	return !lockingClause.MatchString(query)
+}
+
+func (c *Cluster) reader(ctx context.Context, query string) *sql.DB {
+	if isReplicaRead(ctx, query) {
+		return c.Replica()
+	}
+	return c.primary
+}
+
+//ExecContext executes a query on the primary
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+//QueryContext executes a SELECT on a replica, while a locking read such as SELECT ... FOR UPDATE,
+//any other statement, or a ctx returned by UsePrimary goes to the primary
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.reader(ctx, query).QueryContext(ctx, query, args...)
+}
+
+//QueryRowContext is the same as QueryContext but returns at most one row
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.reader(ctx, query).QueryRowContext(ctx, query, args...)
+}
+
+//BeginTx starts a transaction on the primary, all the queries of a transaction go to the primary
+func (c *Cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.primary.BeginTx(ctx, opts)
+}
+
+//Close stops health checking and closes the primary and the replicas
+func (c *Cluster) Close() error {
+	var err error
+	c.closed.Do(func() {
+		close(c.stop)
+		c.wg.Wait()
+		err = c.primary.Close()
+		for _, r := range c.replicas {
+			if errClose := r.db.Close(); nil == err {
+				err = errClose
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,167 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockCluster(t *testing.T, n int, option *ClusterOption) (*Cluster, []sqlmock.Sqlmock) {
+	primary, _, err := sqlmock.New()
+	require.NoError(t, err)
+	var replicas []*sql.DB
+	var mocks []sqlmock.Sqlmock
+	for i := 0; i < n; i++ {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		replicas = append(replicas, db)
+		mocks = append(mocks, mock)
+	}
+	return newCluster(primary, replicas, option), mocks
+}
+
+func TestClusterRoundRobin(t *testing.T) {
+	ass := assert.New(t)
+	c, mocks := newMockCluster(t, 3, NewCluster(nil))
+	defer c.Close()
+	var picked []*sql.DB
+	for i := 0; i < 4; i++ {
+		picked = append(picked, c.Replica())
+	}
+	ass.Equal([]*sql.DB{c.replicas[0].db, c.replicas[1].db, c.replicas[2].db, c.replicas[0].db}, picked)
+
+	mocks[0].ExpectPing()
+	mocks[1].ExpectPing().WillReturnError(errors.New("gone away"))
+	mocks[2].ExpectPing()
+	c.Check(context.Background())
+	for i := 0; i < 4; i++ {
+		ass.NotEqual(c.replicas[1].db, c.Replica(), "the failed replica is removed")
+	}
+
+	mocks[1].ExpectPing()
+	mocks[0].ExpectPing().WillReturnError(errors.New("gone away"))
+	mocks[2].ExpectPing().WillReturnError(errors.New("gone away"))
+	c.Check(context.Background())
+	ass.Equal(c.replicas[1].db, c.Replica(), "the replica is added back")
+
+	mocks[1].ExpectPing().WillReturnError(errors.New("gone away"))
+	mocks[0].ExpectPing().WillReturnError(errors.New("gone away"))
+	mocks[2].ExpectPing().WillReturnError(errors.New("gone away"))
+	c.Check(context.Background())
+	ass.Equal(c.Primary(), c.Replica(), "fall back to the primary")
+	for _, mock := range mocks {
+		ass.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestClusterPolicy(t *testing.T) {
+	ass := assert.New(t)
+	c, mocks := newMockCluster(t, 3, NewCluster(nil).Policy(LeastLatency).HealthCheck(0, 20*time.Millisecond))
+	defer c.Close()
+	mocks[0].ExpectPing().WillDelayFor(10 * time.Millisecond)
+	mocks[1].ExpectPing()
+	mocks[2].ExpectPing().WillDelayFor(50 * time.Millisecond)
+	c.Check(context.Background())
+	ass.Equal(c.replicas[1].db, c.Replica())
+	ass.Equal(int32(0), c.replicas[2].healthy, "timeout")
+
+	c.option.policy = Random
+	picked := make(map[*sql.DB]bool)
+	for i := 0; i < 100; i++ {
+		picked[c.Replica()] = true
+	}
+	ass.Equal(map[*sql.DB]bool{c.replicas[0].db: true, c.replicas[1].db: true}, picked)
+}
+
+func TestClusterRouting(t *testing.T) {
+	should := require.New(t)
+	primary, primaryMock, err := sqlmock.New()
+	should.NoError(err)
+	replica, replicaMock, err := sqlmock.New()
+	should.NoError(err)
+	c := newCluster(primary, []*sql.DB{replica}, NewCluster(nil))
+	defer c.Close()
+	ctx := context.Background()
+
+	replicaMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	replicaMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	primaryMock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	primaryMock.ExpectCommit()
+
+	rows, err := c.QueryContext(ctx, "SELECT id FROM user")
+	should.NoError(err)
+	should.NoError(rows.Close())
+	var id int
+	should.NoError(c.QueryRowContext(ctx, "SELECT id FROM user").Scan(&id))
+	_, err = c.ExecContext(ctx, "UPDATE user SET age=1")
+	should.NoError(err)
+	tx, err := c.BeginTx(ctx, nil)
+	should.NoError(err)
+	should.NoError(tx.QueryRowContext(ctx, "SELECT id FROM user").Scan(&id))
+	should.NoError(tx.Commit())
+
+	for _, query := range []string{
+		"SELECT id FROM user WHERE (id=?) FOR UPDATE",
+		"select id from user where (id=?) lock in share mode",
+		"SELECT id FROM user FOR NO KEY UPDATE",
+		"INSERT INTO user (name) VALUES ($1) RETURNING id",
+		"WITH d AS (DELETE FROM user RETURNING id) SELECT id FROM d",
+	} {
+		primaryMock.ExpectQuery("").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		rows, err = c.QueryContext(ctx, query)
+		should.NoError(err, query)
+		should.NoError(rows.Close())
+	}
+	primaryMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	should.NoError(c.QueryRowContext(UsePrimary(ctx), "SELECT id FROM user").Scan(&id))
+	replicaMock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	should.NoError(c.QueryRowContext(ctx, "(SELECT id FROM user) UNION (SELECT id FROM vip)").Scan(&id))
+	should.NoError(primaryMock.ExpectationsWereMet())
+	should.NoError(replicaMock.ExpectationsWereMet())
+}
+
+func TestClusterOpen(t *testing.T) {
+	should := require.New(t)
+	// sqlmock dsns must be unique
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	primary := New("db", "user", "password", "primary"+suffix).Driver("sqlmock")
+	replica := New("db", "user", "password", "replica"+suffix).Driver("sqlmock")
+	_, primaryMock, err := sqlmock.NewWithDSN(realDSN(primary))
+	should.NoError(err)
+	primaryMock.ExpectClose()
+	_, replicaMock, err := sqlmock.NewWithDSN(realDSN(replica), sqlmock.MonitorPingsOption(true))
+	should.NoError(err)
+	replicaMock.ExpectPing().WillReturnError(errors.New("gone away"))
+	replicaMock.ExpectPing()
+	replicaMock.ExpectClose()
+
+	c, err := NewCluster(primary, replica).HealthCheck(10*time.Millisecond, 0).Open(true)
+	should.NoError(err)
+	should.Equal(c.Primary(), c.Replica(), "the replica failed the first check")
+	should.Eventually(func() bool {
+		return c.Primary() != c.Replica()
+	}, time.Second, 5*time.Millisecond, "the replica is added back by health checking")
+	should.NoError(c.Close())
+	should.NoError(c.Close(), "Close is idempotent")
+
+	_, err = NewCluster(New("db", "user", "password", "primary").Driver("unknown")).Open(false)
+	should.Error(err)
+
+	failed := New("db", "user", "password", "failed"+suffix).Driver("sqlmock")
+	_, failedMock, err := sqlmock.NewWithDSN(realDSN(failed), sqlmock.MonitorPingsOption(true))
+	should.NoError(err)
+	failedMock.ExpectPing().WillReturnError(errors.New("gone away"))
+	failedMock.ExpectClose()
+	_, err = NewCluster(failed).Open(true)
+	should.EqualError(err, "gone away")
+	should.NoError(failedMock.ExpectationsWereMet(), "the primary failing to ping is closed")
+}
@@ -3,6 +3,7 @@ package manager
 import (
 	"database/sql"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -149,7 +150,16 @@ type Option struct {
 	password string
 	host     string
 	port     int
+	// protocol is the network of the address, empty for tcp. The host is the whole
+	// address of the other protocols, like the path of the socket of unix
+	protocol string
 	settings []Setting
+	// pool sets the connection pool of the *sql.DB
+	pool        []func(*sql.DB)
+	pingRetries int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	sleep       func(time.Duration)
 }
 
 //New returns an Option
@@ -161,6 +171,7 @@ func New(dbName, user, password, host string) *Option {
 		host:     host,
 		port:     defaultPort,
 		driver:   defaultDriver,
+		sleep:    time.Sleep,
 	}
 }
 
@@ -184,13 +195,14 @@ func (o *Option) Set(sets ...Setting) *Option {
 
 //Open is used for creating a *sql.DB
 //Use it at the last
+//If ping=true, the ping is retried as PingRetry sets
 func (o *Option) Open(ping bool) (*sql.DB, error) {
 	db, err := open(o)
 	if nil != err {
 		return nil, err
 	}
 	if ping {
-		err = db.Ping()
+		err = o.ping(db)
 	}
 	return db, err
 }
@@ -204,10 +216,21 @@ func concatDSN(settings []Setting) string {
 }
 
 func realDSN(info *Option) string {
-	format := "%s:%s@tcp(%s:%d)/%s?%s"
-	return strings.TrimRight(fmt.Sprintf(format, info.user, info.password, info.host, info.port, info.dbName, concatDSN(info.settings)), "?")
+	format := "%s:%s@%s(%s)/%s?%s"
+	protocol, address := info.protocol, info.host
+	if "" == protocol || "tcp" == protocol {
+		protocol, address = "tcp", net.JoinHostPort(info.host, strconv.Itoa(info.port))
+	}
+	return strings.TrimRight(fmt.Sprintf(format, info.user, info.password, protocol, address, info.dbName, concatDSN(info.settings)), "?")
 }
 
 func open(o *Option) (*sql.DB, error) {
-	return sql.Open(o.driver, realDSN(o))
+	db, err := sql.Open(o.driver, realDSN(o))
+	if nil != err {
+		return nil, err
+	}
+	for _, f := range o.pool {
+		f(db)
+	}
+	return db, nil
 }
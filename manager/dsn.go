@@ -0,0 +1,185 @@
+package manager
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	redacted      = "xxxxx"
+	defaultSocket = "/tmp/mysql.sock"
+)
+
+var (
+	boolParams = map[string]func(bool) Setting{
+		"allowAllFiles":           SetAllowAllFiles,
+		"allowCleartextPasswords": SetAllowCleartextPasswords,
+		"allowNativePasswords":    SetAllowNativePasswords,
+		"autocommit":              SetAutoCommit,
+		"clientFoundRows":         SetClientFoundRows,
+		"columnsWithAlias":        SetColumnsWithAlias,
+		"interpolateParams":       SetInterpolateParams,
+		"parseTime":               SetParseTime,
+		"strict":                  SetStrict,
+	}
+	timeParams = map[string]func(time.Duration) Setting{
+		"timeout":      SetTimeout,
+		"readTimeout":  SetReadTimeout,
+		"writeTimeout": SetWriteTimeout,
+	}
+	stringParams = map[string]func(string) Setting{
+		"charset":   SetCharset,
+		"collation": SetCollation,
+		"loc":       SetLoc,
+	}
+)
+
+//SetParam sets a param which has no Set* function, the value is used as it is,
+//so escape it with url.QueryEscape if necessary
+func SetParam(param, value string) Setting {
+	return func(source string) string {
+		return fmt.Sprintf(cDSNFormat, source, param, value)
+	}
+}
+
+//ParseDSN parses a DSN in the format of [username[:password]@][protocol[(address)]]/dbname[?param1=value1&...&paramN=valueN]
+//into an Option. The protocol could be tcp, unix or the ones registered to the driver. The known params are mapped to their Set* functions, the others are kept by SetParam.
+//realDSN of the Option is the same as dsn, except that the default parts are filled,
+//for example the port 3306 of tcp and the socket /tmp/mysql.sock of unix, and the durations are formatted by time.Duration.
+func ParseDSN(dsn string) (*Option, error) {
+	o := New("", "", "", "")
+	// the password may contain / and @, while the dbname may not
+	slash := strings.LastIndexByte(dsn, '/')
+	if -1 == slash {
+		return nil, fmt.Errorf("[manager] invalid DSN %s: missing the slash before dbname", RedactDSN(dsn))
+	}
+	o.dbName = dsn[slash+1:]
+	params := ""
+	if idx := strings.IndexByte(o.dbName, '?'); -1 != idx {
+		o.dbName, params = o.dbName[:idx], o.dbName[idx+1:]
+	}
+	address := dsn[:slash]
+	if at := strings.LastIndexByte(address, '@'); -1 != at {
+		o.user, address = address[:at], address[at+1:]
+		if colon := strings.IndexByte(o.user, ':'); -1 != colon {
+			o.user, o.password = o.user[:colon], o.user[colon+1:]
+		}
+	}
+	if err := o.parseAddress(address); nil != err {
+		return nil, fmt.Errorf("[manager] invalid DSN %s: %v", RedactDSN(dsn), err)
+	}
+	if err := o.parseParams(params); nil != err {
+		return nil, fmt.Errorf("[manager] invalid DSN %s: %v", RedactDSN(dsn), err)
+	}
+	return o, nil
+}
+
+func (o *Option) parseAddress(address string) error {
+	o.host = "127.0.0.1"
+	if "" == address {
+		return nil
+	}
+	protocol := address
+	if open := strings.IndexByte(address, '('); -1 != open {
+		if !strings.HasSuffix(address, ")") {
+			return fmt.Errorf("unclosed address %s", address)
+		}
+		protocol, address = address[:open], address[open+1:len(address)-1]
+	} else {
+		address = ""
+	}
+	if "tcp" != protocol {
+		// the address of the other protocols is kept as it is
+		o.protocol, o.host = protocol, address
+		if "" != address {
+			return nil
+		}
+		if "unix" != protocol {
+			return fmt.Errorf("missing the address of protocol %s", protocol)
+		}
+		o.host = defaultSocket
+		return nil
+	}
+	if "" == address {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if nil != err {
+		// no port
+		o.host = strings.Trim(address, "[]")
+		return nil
+	}
+	o.host = host
+	o.port, err = strconv.Atoi(port)
+	return err
+}
+
+func (o *Option) parseParams(params string) error {
+	if "" == params {
+		return nil
+	}
+	for _, param := range strings.Split(params, "&") {
+		key, value := param, ""
+		if idx := strings.IndexByte(param, '='); -1 != idx {
+			key, value = param[:idx], param[idx+1:]
+		}
+		setting, err := paramSetting(key, value)
+		if nil != err {
+			return err
+		}
+		o.settings = append(o.settings, setting)
+	}
+	return nil
+}
+
+func paramSetting(key, value string) (Setting, error) {
+	if set, ok := boolParams[key]; ok {
+		b, err := strconv.ParseBool(value)
+		if nil != err {
+			return nil, fmt.Errorf("invalid value of %s: %s", key, value)
+		}
+		return set(b), nil
+	}
+	if set, ok := timeParams[key]; ok {
+		d, err := time.ParseDuration(value)
+		if nil != err || d < time.Millisecond || d >= 24*time.Hour {
+			return nil, fmt.Errorf("invalid value of %s: %s", key, value)
+		}
+		return set(d), nil
+	}
+	// an empty value is dropped by the Set* function
+	if set, ok := stringParams[key]; ok && "" != value {
+		return set(value), nil
+	}
+	return SetParam(key, value), nil
+}
+
+//DSN returns the DSN of the Option, which is passed to sql.Open
+func (o *Option) DSN() string {
+	return realDSN(o)
+}
+
+//String returns the DSN with the password redacted, it's safe for logging
+func (o *Option) String() string {
+	return RedactDSN(realDSN(o))
+}
+
+//RedactDSN replaces the password in dsn with xxxxx, so that it's safe for logging
+func RedactDSN(dsn string) string {
+	slash := strings.LastIndexByte(dsn, '/')
+	if -1 == slash {
+		slash = len(dsn)
+	}
+	at := strings.LastIndexByte(dsn[:slash], '@')
+	if -1 == at {
+		return dsn
+	}
+	colon := strings.IndexByte(dsn[:at], ':')
+	if -1 == colon || colon+1 == at {
+		return dsn
+	}
+	return dsn[:colon+1] + redacted + dsn[at:]
+}
@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDSN(t *testing.T) {
+	var data = []struct {
+		dsn      string
+		expected string
+	}{
+		{"user:password@tcp(localhost:3306)/dbname", ""},
+		{"user:p@ss/w:rd@tcp(10.0.0.1:3302)/dbname?charset=utf8mb4&parseTime=true&loc=Asia%2FShanghai", ""},
+		{"user:password@tcp(localhost:3306)/dbname?timeout=1s&readTimeout=500ms&maxAllowedPacket=0&tls=skip-verify", ""},
+		{"user:password@tcp([::1]:3306)/dbname", ""},
+		{"user@tcp(localhost)/dbname?timeout=90s", "user:@tcp(localhost:3306)/dbname?timeout=1m30s"},
+		{"user:password@/dbname?charset=", "user:password@tcp(127.0.0.1:3306)/dbname?charset="},
+		{"/dbname", ":@tcp(127.0.0.1:3306)/dbname"},
+		{"user:password@tcp/dbname", "user:password@tcp(127.0.0.1:3306)/dbname"},
+		{"user:password@unix(/var/run/mysqld/mysqld.sock)/dbname?charset=utf8", ""},
+		{"user:password@unix/dbname", "user:password@unix(/tmp/mysql.sock)/dbname"},
+		{"user:password@mydialer(proxy:1234)/dbname", ""},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		o, err := ParseDSN(tc.dsn)
+		if !ass.NoError(err, tc.dsn) {
+			continue
+		}
+		if "" == tc.expected {
+			tc.expected = tc.dsn
+		}
+		ass.Equal(tc.expected, realDSN(o), tc.dsn)
+	}
+
+	o, err := ParseDSN("user:p@ss/w:rd@tcp(10.0.0.1:3302)/dbname?charset=utf8")
+	ass.NoError(err)
+	ass.Equal("user", o.user)
+	ass.Equal("p@ss/w:rd", o.password)
+	ass.Equal("10.0.0.1", o.host)
+	ass.Equal(3302, o.port)
+	ass.Equal("dbname", o.dbName)
+	o.Set(SetTimeout(time.Second))
+	ass.Equal("user:p@ss/w:rd@tcp(10.0.0.1:3302)/dbname?charset=utf8&timeout=1s", o.DSN(), "the parsed Option works as others")
+}
+
+func TestParseDSN_Error(t *testing.T) {
+	var data = []string{
+		"user:password@tcp(localhost:3306)",
+		"user:password@mydialer/dbname",
+		"user:password@unix(/tmp/mysql.sock/dbname",
+		"user:password@tcp(localhost:3306/dbname",
+		"user:password@tcp(localhost:port)/dbname",
+		"user:password@tcp(localhost:3306)/dbname?parseTime=yes",
+		"user:password@tcp(localhost:3306)/dbname?timeout=1",
+		"user:password@tcp(localhost:3306)/dbname?timeout=24h",
+	}
+	ass := assert.New(t)
+	for _, dsn := range data {
+		_, err := ParseDSN(dsn)
+		ass.Error(err, dsn)
+		if nil != err {
+			ass.NotContains(err.Error(), "password", "the password is redacted")
+		}
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	var data = []struct {
+		dsn      string
+		expected string
+	}{
+		{"user:password@tcp(localhost:3306)/dbname", "user:xxxxx@tcp(localhost:3306)/dbname"},
+		{"user:p@ss/w:rd@tcp(localhost:3306)/dbname?loc=Local", "user:xxxxx@tcp(localhost:3306)/dbname?loc=Local"},
+		{"user:@tcp(localhost:3306)/dbname", "user:@tcp(localhost:3306)/dbname"},
+		{"user@tcp(localhost:3306)/dbname", "user@tcp(localhost:3306)/dbname"},
+		{"/dbname", "/dbname"},
+		{"user:password@tcp(localhost:3306)", "user:xxxxx@tcp(localhost:3306)"},
+	}
+	ass := assert.New(t)
+	for _, tc := range data {
+		ass.Equal(tc.expected, RedactDSN(tc.dsn), tc.dsn)
+	}
+	o := New("dbname", "user", "password", "localhost")
+	ass.Equal("user:xxxxx@tcp(localhost:3306)/dbname", fmt.Sprint(o))
+	ass.Equal("user:password@tcp(localhost:3306)/dbname", o.DSN())
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"database/sql"
+	"time"
+)
+
+//SetMaxOpenConns sets the maximum number of open connections of the *sql.DB, see sql.DB.SetMaxOpenConns
+func (o *Option) SetMaxOpenConns(n int) *Option {
+	o.pool = append(o.pool, func(db *sql.DB) {
+		db.SetMaxOpenConns(n)
+	})
+	return o
+}
+
+//SetMaxIdleConns sets the maximum number of idle connections of the *sql.DB, see sql.DB.SetMaxIdleConns
+func (o *Option) SetMaxIdleConns(n int) *Option {
+	o.pool = append(o.pool, func(db *sql.DB) {
+		db.SetMaxIdleConns(n)
+	})
+	return o
+}
+
+//SetConnMaxLifetime sets the maximum amount of time a connection may be reused, see sql.DB.SetConnMaxLifetime
+func (o *Option) SetConnMaxLifetime(d time.Duration) *Option {
+	o.pool = append(o.pool, func(db *sql.DB) {
+		db.SetConnMaxLifetime(d)
+	})
+	return o
+}
+
+//SetConnMaxIdleTime sets the maximum amount of time a connection may be idle, see sql.DB.SetConnMaxIdleTime.
+//It takes effect since go1.15 and is ignored before.
+func (o *Option) SetConnMaxIdleTime(d time.Duration) *Option {
+	o.pool = append(o.pool, func(db *sql.DB) {
+		setConnMaxIdleTime(db, d)
+	})
+	return o
+}
+
+//PingRetry makes Open(true) retry the ping at most retries times before giving up,
+//waiting backoff before the first retry and doubling it each time, up to maxBackoff.
+//It helps services start before the database is fully ready.
+func (o *Option) PingRetry(retries int, backoff, maxBackoff time.Duration) *Option {
+	o.pingRetries = retries
+	o.backoff = backoff
+	o.maxBackoff = maxBackoff
+	return o
+}
+
+func (o *Option) ping(db *sql.DB) error {
+	backoff := o.backoff
+	err := db.Ping()
+	for i := 0; i < o.pingRetries && nil != err; i++ {
+		o.sleep(backoff)
+		if backoff *= 2; o.maxBackoff > 0 && backoff > o.maxBackoff {
+			backoff = o.maxBackoff
+		}
+		err = db.Ping()
+	}
+	return err
+}
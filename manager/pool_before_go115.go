@@ -0,0 +1,12 @@
+//go:build !go1.15
+// +build !go1.15
+
+package manager
+
+import (
+	"database/sql"
+	"time"
+)
+
+//setConnMaxIdleTime does nothing since sql.DB.SetConnMaxIdleTime is not available before go1.15
+func setConnMaxIdleTime(db *sql.DB, d time.Duration) {}
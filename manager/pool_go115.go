@@ -0,0 +1,13 @@
+//go:build go1.15
+// +build go1.15
+
+package manager
+
+import (
+	"database/sql"
+	"time"
+)
+
+func setConnMaxIdleTime(db *sql.DB, d time.Duration) {
+	db.SetConnMaxIdleTime(d)
+}
@@ -0,0 +1,63 @@
+package manager
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockOption(t *testing.T, monitorPings bool) (*Option, sqlmock.Sqlmock) {
+	// sqlmock dsns must be unique
+	o := New("db", "user", "password", t.Name()+strconv.FormatInt(time.Now().UnixNano(), 10)).Driver("sqlmock")
+	_, mock, err := sqlmock.NewWithDSN(realDSN(o), sqlmock.MonitorPingsOption(monitorPings))
+	require.NoError(t, err)
+	return o, mock
+}
+
+func TestPoolOptions(t *testing.T) {
+	should := require.New(t)
+	o, _ := newMockOption(t, false)
+	db, err := o.SetMaxOpenConns(10).
+		SetMaxIdleConns(5).
+		SetConnMaxLifetime(time.Minute).
+		SetConnMaxIdleTime(time.Second).
+		Open(false)
+	should.NoError(err)
+	should.Equal(10, db.Stats().MaxOpenConnections)
+	should.Len(o.pool, 4)
+}
+
+func TestPingRetry(t *testing.T) {
+	should := require.New(t)
+	o, mock := newMockOption(t, true)
+	var delays []time.Duration
+	o.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+	errPing := errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		mock.ExpectPing().WillReturnError(errPing)
+	}
+	mock.ExpectPing()
+	_, err := o.PingRetry(5, 100*time.Millisecond, 300*time.Millisecond).Open(true)
+	should.NoError(err)
+	should.Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, delays)
+	should.NoError(mock.ExpectationsWereMet())
+
+	o, mock = newMockOption(t, true)
+	delays = nil
+	o.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+	for i := 0; i < 3; i++ {
+		mock.ExpectPing().WillReturnError(errPing)
+	}
+	_, err = o.PingRetry(2, time.Second, 0).Open(true)
+	should.Equal(errPing, err)
+	should.Equal([]time.Duration{time.Second, 2 * time.Second}, delays)
+	should.NoError(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrStop stops ScanEach without an error when it's returned by the callback
+	ErrStop = errors.New("[scanner]: stop scanning")
+	// ErrInvalidCallback means the callback of ScanEach isn't a func(*T) error
+	ErrInvalidCallback = errors.New("[scanner]: callback must be a func(*T) error")
+)
+
+// Iterator binds rows one by one, so only the current row is kept in memory.
+// Typical usage:
+//
+//	it := scanner.NewIterator(rows, &User{})
+//	defer it.Close()
+//	for it.Next() {
+//		user := it.Value().(*User)
+//		// ...
+//	}
+//	err := it.Err()
+type Iterator struct {
+	binder *rowBinder
+	typ    reflect.Type
+	value  interface{}
+	err    error
+}
+
+// NewIterator returns an Iterator of rows, target is a pointer of the type
+// each row binds to, it's used only for its type.
+func NewIterator(rows Rows, target interface{}) *Iterator {
+	return NewIteratorWithMode(rows, target, defaultMode)
+}
+
+// NewIteratorWithMode is the same as NewIterator but in the given mode instead of the one set by SetMode
+func NewIteratorWithMode(rows Rows, target interface{}, mode Mode) *Iterator {
+	it := &Iterator{}
+	if nil == target || reflect.TypeOf(target).Kind() != reflect.Ptr {
+		it.err = ErrTargetNotSettable
+		return it
+	}
+	it.typ = reflect.TypeOf(target).Elem()
+	typ, ok := structType(it.typ)
+	if !ok {
+		it.err = ErrNoneStructTarget
+		return it
+	}
+	it.binder, it.err = newRowBinder(rows, typ, mode, nil)
+	return it
+}
+
+// Next binds the next row to a new value, it returns false
+// when there are no more rows or an error occurs
+func (it *Iterator) Next() bool {
+	it.value = nil
+	if nil != it.err || !it.binder.rows.Next() {
+		return false
+	}
+	value := reflect.New(it.typ)
+	if it.err = it.binder.bind(allocStruct(value.Elem())); nil != it.err {
+		return false
+	}
+	it.value = value.Interface()
+	return true
+}
+
+// Value returns the row bound by the last call of Next, a pointer of the type of target.
+// Each row is bound to a new value, so it's safe to keep it.
+func (it *Iterator) Value() interface{} {
+	return it.value
+}
+
+// Err returns the error occurred during the iteration
+func (it *Iterator) Err() error {
+	if nil != it.err {
+		return it.err
+	}
+	if nil != it.binder {
+		return rowsErr(it.binder.rows)
+	}
+	return nil
+}
+
+// Close closes the rows, call it when stopping the iteration early
+func (it *Iterator) Close() error {
+	if nil == it.binder {
+		return nil
+	}
+	return it.binder.rows.Close()
+}
+
+// ScanEach binds rows one by one and calls fn with each of them, fn must be a func(*T) error.
+// It stops at the first error returned by fn, return ErrStop to stop without an error.
+// Don't forget to close the rows.
+func ScanEach(rows Rows, fn interface{}) error {
+	return ScanEachWithMode(rows, fn, defaultMode)
+}
+
+// ScanEachWithMode is the same as ScanEach but in the given mode instead of the one set by SetMode
+func ScanEachWithMode(rows Rows, fn interface{}, mode Mode) error {
+	if nil == fn {
+		return ErrInvalidCallback
+	}
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.In(0).Kind() != reflect.Ptr ||
+		ft.NumOut() != 1 || ft.Out(0) != _errorType {
+		return ErrInvalidCallback
+	}
+	it := NewIteratorWithMode(rows, reflect.Zero(ft.In(0)).Interface(), mode)
+	for it.Next() {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(it.Value())})
+		if err, _ := out[0].Interface().(error); nil != err {
+			if err == ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.Err()
+}
+
+var _errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// rowsErr returns the error encountered during the iteration of rows if rows reports it,
+// as *sql.Rows does
+func rowsErr(rows Rows) error {
+	if r, ok := rows.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type iterUser struct {
+	Name string `ddb:"name"`
+	Age  int    `ddb:"age"`
+}
+
+func TestIterator(t *testing.T) {
+	should := require.New(t)
+	rows := &fakeRows{
+		columns: []string{"name", "age"},
+		dataset: [][]interface{}{
+			{"deen", int64(23)},
+			{"caibirdme", int64(24)},
+		},
+	}
+	it := NewIterator(rows, &iterUser{})
+	var users []*iterUser
+	for it.Next() {
+		users = append(users, it.Value().(*iterUser))
+	}
+	should.NoError(it.Err())
+	should.Equal([]*iterUser{{"deen", 23}, {"caibirdme", 24}}, users)
+	should.Nil(it.Value())
+	should.Equal(errCloseForTest, it.Close())
+
+	it = NewIterator(&fakeRows{
+		columns: []string{"name", "age"},
+		dataset: [][]interface{}{{"deen", "23"}},
+	}, &iterUser{})
+	should.False(it.Next())
+	should.IsType(ScanErr{}, it.Err())
+
+	it = NewIterator(nil, &iterUser{})
+	should.False(it.Next())
+	should.Equal(ErrNilRows, it.Err())
+	should.NoError(it.Close())
+	it = NewIterator(rows, iterUser{})
+	should.False(it.Next())
+	should.Equal(ErrTargetNotSettable, it.Err())
+}
+
+func TestIteratorRowsErr(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	rowErr := errors.New("connection lost")
+	mock.ExpectQuery("select \\* from tb").WillReturnRows(sqlmock.NewRows([]string{"name", "age"}).
+		AddRow("deen", int64(23)).
+		AddRow("caibirdme", int64(24)).
+		RowError(1, rowErr))
+	rows, err := db.Query("select * from tb")
+	should.NoError(err)
+	it := NewIterator(rows, &iterUser{})
+	defer it.Close()
+	count := 0
+	for it.Next() {
+		count++
+	}
+	should.Equal(1, count)
+	should.Equal(rowErr, it.Err())
+}
+
+func TestScanEach(t *testing.T) {
+	should := require.New(t)
+	newRows := func() *fakeRows {
+		return &fakeRows{
+			columns: []string{"name", "age"},
+			dataset: [][]interface{}{
+				{"deen", int64(23)},
+				{"caibirdme", int64(24)},
+				{"tony", int64(25)},
+			},
+		}
+	}
+	var names []string
+	err := ScanEach(newRows(), func(u *iterUser) error {
+		names = append(names, u.Name)
+		return nil
+	})
+	should.NoError(err)
+	should.Equal([]string{"deen", "caibirdme", "tony"}, names)
+
+	names = nil
+	err = ScanEach(newRows(), func(u *iterUser) error {
+		names = append(names, u.Name)
+		if u.Age >= 24 {
+			return ErrStop
+		}
+		return nil
+	})
+	should.NoError(err)
+	should.Equal([]string{"deen", "caibirdme"}, names)
+
+	fnErr := errors.New("write failed")
+	err = ScanEach(newRows(), func(u *iterUser) error {
+		return fnErr
+	})
+	should.Equal(fnErr, err)
+
+	for _, fn := range []interface{}{nil, 1, func(u iterUser) error { return nil }, func(u *iterUser) {}} {
+		should.Equal(ErrInvalidCallback, ScanEach(newRows(), fn))
+	}
+}
@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrMapTarget means the target of ScanIntoMap is not a pointer of map
+var ErrMapTarget = errors.New("[scanner]: target must be a pointer of map")
+
+// ScanIntoMap scans rows into the map target points to, keyed by the value of the column key.
+// The element of the map may be a struct, a pointer of struct or a scalar,
+// the last row wins if rows have the same key:
+//
+//	var users map[int64]*User
+//	err := scanner.ScanIntoMap(rows, "id", &users)
+//
+// If the element is a slice, rows having the same key are grouped into it in order:
+//
+//	var orders map[int64][]Order
+//	err := scanner.ScanIntoMap(rows, "user_id", &orders)
+//
+// A scalar element binds to the only column besides the key.
+// Rows are merged into the map if it's not nil. Don't forget to close the rows.
+func ScanIntoMap(rows Rows, key string, target interface{}) error {
+	return scanIntoMap(rows, key, target, defaultMode)
+}
+
+// ScanIntoMapWithMode is the same as ScanIntoMap but in the given mode instead of the one set by SetMode
+func ScanIntoMapWithMode(rows Rows, key string, target interface{}, mode Mode) error {
+	return scanIntoMap(rows, key, target, mode)
+}
+
+// ScanIntoMapClose is the same as ScanIntoMap and closes the rows
+func ScanIntoMapClose(rows Rows, key string, target interface{}) error {
+	return ScanIntoMapCloseWithMode(rows, key, target, defaultMode)
+}
+
+// ScanIntoMapCloseWithMode is the same as ScanIntoMapWithMode and closes the rows
+func ScanIntoMapCloseWithMode(rows Rows, key string, target interface{}, mode Mode) error {
+	err := scanIntoMap(rows, key, target, mode)
+	if nil != rows {
+		errClose := rows.Close()
+		if err == nil {
+			err = newCloseErr(errClose)
+		}
+	}
+	return err
+}
+
+func scanIntoMap(rows Rows, key string, target interface{}, mode Mode) error {
+	if nil == target || reflect.TypeOf(target).Kind() != reflect.Ptr || reflect.ValueOf(target).IsNil() {
+		return ErrTargetNotSettable
+	}
+	targetObj := reflect.ValueOf(target).Elem()
+	if targetObj.Kind() != reflect.Map {
+		return ErrMapTarget
+	}
+	mapType := targetObj.Type()
+	elemType := mapType.Elem()
+	group := elemType.Kind() == reflect.Slice && elemType.Elem().Kind() != reflect.Uint8
+	if group {
+		elemType = elemType.Elem()
+	}
+	keyValue := reflect.New(mapType.Key()).Elem()
+	binder, err := newRowBinder(rows, elemType, mode, &keyColumn{
+		name:  key,
+		plan:  valuePlan(mapType.Key(), key),
+		value: keyValue,
+	})
+	if nil != err {
+		return err
+	}
+	if targetObj.IsNil() {
+		targetObj.Set(reflect.MakeMap(mapType))
+	}
+	for rows.Next() {
+		elem := reflect.New(elemType).Elem()
+		if err = binder.bind(elem); nil != err {
+			return err
+		}
+		if group {
+			elems := targetObj.MapIndex(keyValue)
+			if !elems.IsValid() {
+				elems = reflect.MakeSlice(mapType.Elem(), 0, 1)
+			}
+			elem = reflect.Append(elems, elem)
+		}
+		targetObj.SetMapIndex(keyValue, elem)
+	}
+	return rowsErr(rows)
+}
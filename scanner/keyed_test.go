@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedOrder struct {
+	ID    int64   `ddb:"id"`
+	Price float64 `ddb:"price"`
+}
+
+func TestScanIntoMap(t *testing.T) {
+	should := require.New(t)
+	newRows := func() *fakeRows {
+		return &fakeRows{
+			columns: []string{"user_id", "id", "price"},
+			dataset: [][]interface{}{
+				{int64(1), int64(10), 1.5},
+				{[]byte("2"), int64(11), 2.5},
+				{int64(1), int64(12), 3.5},
+			},
+		}
+	}
+	var orders map[int64]*keyedOrder
+	should.NoError(ScanIntoMap(newRows(), "user_id", &orders))
+	should.Equal(map[int64]*keyedOrder{1: {ID: 12, Price: 3.5}, 2: {ID: 11, Price: 2.5}}, orders)
+
+	var groups map[int64][]keyedOrder
+	should.NoError(ScanIntoMap(newRows(), "user_id", &groups))
+	should.Equal(map[int64][]keyedOrder{
+		1: {{ID: 10, Price: 1.5}, {ID: 12, Price: 3.5}},
+		2: {{ID: 11, Price: 2.5}},
+	}, groups)
+
+	byID := map[string]keyedOrder{"9": {ID: 9}}
+	should.NoError(ScanIntoMap(newRows(), "id", &byID))
+	should.Equal(map[string]keyedOrder{"9": {ID: 9}, "10": {ID: 10, Price: 1.5}, "11": {ID: 11, Price: 2.5}, "12": {ID: 12, Price: 3.5}}, byID)
+
+	should.NoError(scanIntoMap(newRows(), "user_id", &orders, Strict), "the key column is not unknown")
+	var prices map[int64]struct {
+		Price float64 `ddb:"price"`
+	}
+	should.IsType(MismatchErr{}, scanIntoMap(newRows(), "user_id", &prices, Strict), "id is unknown")
+	should.Error(ScanIntoMap(newRows(), "email", &orders))
+	should.Equal(ErrSingleColumn, ScanIntoMap(newRows(), "id", &map[int64]float64{}))
+	should.Equal(ErrMapTarget, ScanIntoMap(newRows(), "id", &[]keyedOrder{}))
+	should.Equal(ErrTargetNotSettable, ScanIntoMap(newRows(), "id", orders))
+	should.Equal(errCloseForTest, ScanIntoMapClose(newRows(), "id", &orders).(CloseErr).err)
+}
+
+func TestScanIntoMapSQLRows(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+		AddRow([]byte("1"), []byte("deen")).
+		AddRow(int64(2), nil).
+		AddRow(int64(3), []byte("caibirdme")))
+	rows, err := db.Query("select")
+	should.NoError(err)
+	var names map[int64]*string
+	should.NoError(ScanIntoMap(rows, "id", &names))
+	deen, caibirdme := "deen", "caibirdme"
+	should.Equal(map[int64]*string{1: &deen, 2: nil, 3: &caibirdme}, names)
+
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"user_id", "id", "price"}).
+		AddRow(int64(1), int64(10), []byte("1.5")).
+		AddRow(int64(1), int64(11), []byte("2.5")))
+	rows, err = db.Query("select")
+	should.NoError(err)
+	var groups map[uint64][]*keyedOrder
+	should.NoError(ScanIntoMap(rows, "user_id", &groups))
+	should.Equal(map[uint64][]*keyedOrder{1: {{ID: 10, Price: 1.5}, {ID: 11, Price: 2.5}}}, groups)
+	should.NoError(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// legacyBindSlice is the way Scan bound rows before rowBinder, it reads the rows into maps
+// first and then binds them one by one. It's kept only as the baseline of the Legacy benchmarks,
+// caller must guarantee to pass a &slice as the second param
+func legacyBindSlice(arr []map[string]interface{}, target interface{}) error {
+	targetObj := reflect.ValueOf(target)
+	if !targetObj.Elem().CanSet() {
+		return ErrTargetNotSettable
+	}
+	length := len(arr)
+	valueArrObj := reflect.MakeSlice(targetObj.Elem().Type(), 0, length)
+	typeObj := valueArrObj.Type().Elem()
+	var err error
+	for i := 0; i < length; i++ {
+		newObj := reflect.New(typeObj)
+		newObjInterface := newObj.Interface()
+		err = legacyBind(arr[i], newObjInterface)
+		if nil != err {
+			return err
+		}
+		valueArrObj = reflect.Append(valueArrObj, newObj.Elem())
+	}
+	targetObj.Elem().Set(valueArrObj)
+	return nil
+}
+
+func legacyBind(result map[string]interface{}, target interface{}) (resp error) {
+	if nil != resp {
+		return
+	}
+	defer func() {
+		if r := recover(); nil != r {
+			resp = fmt.Errorf("error:[%v], stack:[%s]", r, string(debug.Stack()))
+		}
+	}()
+	valueObj := reflect.ValueOf(target).Elem()
+	if !valueObj.CanSet() {
+		return ErrTargetNotSettable
+	}
+	typeObj := valueObj.Type()
+	if typeObj.Kind() == reflect.Ptr {
+		ptrType := typeObj.Elem()
+		newObj := reflect.New(ptrType)
+		newObjInterface := newObj.Interface()
+		err := legacyBind(result, newObjInterface)
+		if nil == err {
+			valueObj.Set(newObj)
+		}
+		return err
+	}
+	typeObjName := typeObj.Name()
+
+	for i := 0; i < valueObj.NumField(); i++ {
+		fieldTypeI := typeObj.Field(i)
+		fieldName := fieldTypeI.Name
+
+		//for convenience
+		wrapErr := func(from, to reflect.Type) ScanErr {
+			return newScanErr(typeObjName, fieldName, from, to)
+		}
+
+		valuei := valueObj.Field(i)
+		if !valuei.CanSet() {
+			continue
+		}
+		tagName, ok := lookUpTagName(fieldTypeI)
+		if !ok || "" == tagName {
+			continue
+		}
+		mapValue, ok := result[tagName]
+		if !ok || mapValue == nil {
+			continue
+		}
+		// if one field is a pointer type, we must allocate memory for it first
+		// except for that the pointer type implements the interface ByteUnmarshaler
+		if fieldTypeI.Type.Kind() == reflect.Ptr && !fieldTypeI.Type.Implements(_byteUnmarshalerType) {
+			valuei.Set(reflect.New(fieldTypeI.Type.Elem()))
+			valuei = valuei.Elem()
+		}
+		err := convert(mapValue, valuei, wrapErr)
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func lookUpTagName(typeObj reflect.StructField) (string, bool) {
+	name, ok := typeObj.Tag.Lookup(tagName())
+	if !ok {
+		return "", false
+	}
+	name = resolveTagName(name)
+	return name, ok
+}
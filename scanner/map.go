@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"database/sql/driver"
 	"errors"
 	"reflect"
 	"strings"
@@ -13,6 +14,7 @@ var (
 
 // Map converts a struct to a map
 // type for each field of the struct must be built-in type
+// fields of embedded structs and nested structs tagged with prefix are flattened
 func Map(target interface{}, useTag string) (map[string]interface{}, error) {
 	if nil == target {
 		return nil, nil
@@ -24,43 +26,112 @@ func Map(target interface{}, useTag string) (map[string]interface{}, error) {
 	if v.Kind() != reflect.Struct {
 		return nil, ErrNoneStructTarget
 	}
-	t := v.Type()
+	fields, err := flattenFields(v.Type(), useTag)
+	if nil != err {
+		return nil, err
+	}
 	result := make(map[string]interface{})
-	for i := 0; i < t.NumField(); i++ {
-		keyName := getKey(t.Field(i), useTag)
-		if "" == keyName {
+	for _, field := range fields {
+		if field.field.Type.Kind() == reflect.Ptr {
+			continue
+		}
+		value, ok := readField(v, field.index)
+		if !ok {
 			continue
 		}
-		result[keyName] = v.Field(i).Interface()
+		result[field.column] = value.Interface()
 	}
 	return result, nil
 }
 
-func isExportedField(name string) bool {
-	return strings.Title(name) == name
+func resolveTagName(tag string) string {
+	idx := strings.IndexByte(tag, ',')
+	if -1 == idx {
+		return tag
+	}
+	return tag[:idx]
 }
 
-func getKey(field reflect.StructField, useTag string) string {
-	if !isExportedField(field.Name) {
-		return ""
+// TagOptions are the options following the column name in a tag,
+// such as `ddb:"id,pk,autoincr"`
+type TagOptions struct {
+	// OmitEmpty omits the field if it holds the zero value
+	OmitEmpty bool
+	// ReadOnly never writes the field
+	ReadOnly bool
+	// PK marks the field as a part of the primary key
+	PK bool
+	// AutoIncr marks the field as an auto increment column
+	AutoIncr bool
+}
+
+// Field is a field of a struct with its column name and options
+type Field struct {
+	Column  string
+	Options TagOptions
+	// Value is the value of the field, a pointer is dereferenced
+	// unless it implements driver.Valuer, and it's nil if the pointer is nil
+	Value interface{}
+	// Empty reports whether the field holds the zero value or a nil pointer
+	Empty bool
+}
+
+// Fields returns the fields of a struct tagged with the tag name used by Scan,
+// in the order they are declared. Fields tagged with "-" are skipped, fields of
+// embedded structs and nested structs tagged with prefix are flattened.
+func Fields(target interface{}) ([]Field, error) {
+	if nil == target {
+		return nil, ErrNoneStructTarget
+	}
+	v := reflect.ValueOf(target)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
 	}
-	if field.Type.Kind() == reflect.Ptr {
-		return ""
+	if v.Kind() != reflect.Struct {
+		return nil, ErrNoneStructTarget
 	}
-	if "" == useTag {
-		return field.Name
+	flatFields, err := flattenFields(v.Type(), tagName())
+	if nil != err {
+		return nil, err
 	}
-	tag, ok := field.Tag.Lookup(useTag)
-	if !ok {
-		return ""
+	var fields []Field
+	for _, field := range flatFields {
+		value, ok := readField(v, field.index)
+		if !ok {
+			continue
+		}
+		fields = append(fields, resolveField(field.column, resolveTagOptions(field.options), value))
 	}
-	return resolveTagName(tag)
+	return fields, nil
 }
 
-func resolveTagName(tag string) string {
-	idx := strings.IndexByte(tag, ',')
-	if -1 == idx {
-		return tag
+var _valuerType = reflect.TypeOf(new(driver.Valuer)).Elem()
+
+func resolveField(column string, options TagOptions, value reflect.Value) Field {
+	field := Field{Column: column, Options: options, Empty: value.IsZero()}
+	if value.Kind() == reflect.Ptr && !value.Type().Implements(_valuerType) {
+		if value.IsNil() {
+			return field
+		}
+		value = value.Elem()
 	}
-	return tag[:idx]
+	field.Value = value.Interface()
+	return field
+}
+
+func resolveTagOptions(tagOptions string) TagOptions {
+	var options TagOptions
+	for _, option := range strings.Split(tagOptions, ",") {
+		switch strings.TrimSpace(option) {
+		case "omitempty":
+			options.OmitEmpty = true
+		case "readonly":
+			options.ReadOnly = true
+		case "pk":
+			options.PK = true
+		case "autoincr":
+			options.AutoIncr = true
+		}
+	}
+	return options
 }
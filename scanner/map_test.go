@@ -1,7 +1,9 @@
 package scanner
 
 import (
+	"database/sql/driver"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,3 +74,41 @@ func TestNonStructInput(t *testing.T) {
 	ass.Nil(m)
 	ass.Equal(ErrNoneStructTarget, err)
 }
+
+type account struct {
+	ID        int64     `ddb:"id,pk,autoincr"`
+	Name      string    `ddb:"name"`
+	Nickname  *string   `ddb:"nickname,omitempty"`
+	Score     *int      `ddb:"score"`
+	CreatedAt time.Time `ddb:"created_at,readonly"`
+	Ignored   string    `ddb:"-"`
+	Untagged  string
+	deleted   bool       `ddb:"deleted"`
+	Valuer    *nullValue `ddb:"valuer"`
+}
+
+type nullValue struct{}
+
+func (*nullValue) Value() (driver.Value, error) {
+	return nil, nil
+}
+
+func TestFields(t *testing.T) {
+	ass := assert.New(t)
+	score := 10
+	fields, err := Fields(&account{Name: "deen", Score: &score})
+	ass.NoError(err)
+	ass.Equal([]Field{
+		{Column: "id", Options: TagOptions{PK: true, AutoIncr: true}, Value: int64(0), Empty: true},
+		{Column: "name", Value: "deen"},
+		{Column: "nickname", Options: TagOptions{OmitEmpty: true}, Empty: true},
+		{Column: "score", Value: 10},
+		{Column: "created_at", Options: TagOptions{ReadOnly: true}, Value: time.Time{}, Empty: true},
+		{Column: "valuer", Value: (*nullValue)(nil), Empty: true},
+	}, fields)
+
+	_, err = Fields(nil)
+	ass.Equal(ErrNoneStructTarget, err)
+	_, err = Fields([]account{})
+	ass.Equal(ErrNoneStructTarget, err)
+}
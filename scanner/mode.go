@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Mode tells Scan how to deal with the columns and fields that don't match
+type Mode uint8
+
+// Lenient ignores the columns binding to no field and the fields missing in the columns,
+// it's the default mode
+const Lenient Mode = 0
+
+const (
+	// UnknownColumns rejects the columns binding to no field
+	UnknownColumns Mode = 1 << iota
+	// MissingFields rejects the tagged fields missing in the columns
+	MissingFields
+	// Strict rejects both unknown columns and missing fields
+	Strict = UnknownColumns | MissingFields
+)
+
+var defaultMode = Lenient
+
+// SetMode sets the mode of Scan, ScanClose, ScanEach, ScanIntoMap and Iterator,
+// call it before scanning, for example in an init function.
+// The WithMode variants of them take a mode per call instead
+func SetMode(mode Mode) {
+	defaultMode = mode
+}
+
+// MismatchErr is returned if the columns don't match the struct in the mode of Scan
+type MismatchErr struct {
+	StructName string
+	// UnknownColumns are the columns binding to no field
+	UnknownColumns []string
+	// MissingFields are the columns of the tagged fields missing in the result
+	MissingFields []string
+}
+
+func (e MismatchErr) Error() string {
+	var parts []string
+	if len(e.UnknownColumns) > 0 {
+		parts = append(parts, "unknown columns: "+strings.Join(e.UnknownColumns, ","))
+	}
+	if len(e.MissingFields) > 0 {
+		parts = append(parts, "missing fields: "+strings.Join(e.MissingFields, ","))
+	}
+	return fmt.Sprintf("[scanner]: columns don't match %s, %s", e.StructName, strings.Join(parts, "; "))
+}
+
+// ScanWithMode is the same as Scan but in the given mode instead of the one set by SetMode
+func ScanWithMode(rows Rows, target interface{}, mode Mode) error {
+	return scan(rows, target, mode)
+}
+
+// checkColumns returns a MismatchErr if the columns don't match the plan of typ in mode
+func checkColumns(typ reflect.Type, plan *structPlan, columns []string, mode Mode) error {
+	if Lenient == mode {
+		return nil
+	}
+	err := MismatchErr{StructName: typ.Name()}
+	selected := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if _, ok := plan.columns[column]; !ok && !selected[column] {
+			err.UnknownColumns = append(err.UnknownColumns, column)
+		}
+		selected[column] = true
+	}
+	for _, column := range plan.order {
+		if !selected[column] {
+			err.MissingFields = append(err.MissingFields, column)
+		}
+	}
+	if (mode&UnknownColumns != 0 && len(err.UnknownColumns) > 0) ||
+		(mode&MissingFields != 0 && len(err.MissingFields) > 0) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type modeUser struct {
+	ID   int64  `ddb:"id"`
+	Name string `ddb:"name"`
+	Age  int    `ddb:"age"`
+}
+
+func TestScanWithMode(t *testing.T) {
+	newRows := func(columns ...string) *fakeRows {
+		row := make([]interface{}, len(columns))
+		for i := range row {
+			row[i] = int64(i)
+		}
+		return &fakeRows{columns: columns, dataset: [][]interface{}{row}}
+	}
+	var data = []struct {
+		columns []string
+		mode    Mode
+		err     error
+	}{
+		{[]string{"id", "name", "age"}, Strict, nil},
+		{[]string{"id", "email", "phone"}, Lenient, nil},
+		{[]string{"id", "email", "email"}, UnknownColumns, MismatchErr{StructName: "modeUser", UnknownColumns: []string{"email"}, MissingFields: []string{"name", "age"}}},
+		{[]string{"id", "email"}, MissingFields, MismatchErr{StructName: "modeUser", UnknownColumns: []string{"email"}, MissingFields: []string{"name", "age"}}},
+		{[]string{"id", "name", "age", "email"}, MissingFields, nil},
+		{[]string{"id"}, UnknownColumns, nil},
+		{[]string{"id", "name", "age", "email"}, Strict, MismatchErr{StructName: "modeUser", UnknownColumns: []string{"email"}}},
+	}
+	should := require.New(t)
+	for _, tc := range data {
+		var users []modeUser
+		err := ScanWithMode(newRows(tc.columns...), &users, tc.mode)
+		should.Equal(tc.err, err, "columns:%v mode:%d", tc.columns, tc.mode)
+	}
+	should.Equal("[scanner]: columns don't match modeUser, unknown columns: email; missing fields: name,age",
+		MismatchErr{StructName: "modeUser", UnknownColumns: []string{"email"}, MissingFields: []string{"name", "age"}}.Error())
+}
+
+func TestSetMode(t *testing.T) {
+	should := require.New(t)
+	SetMode(Strict)
+	defer SetMode(Lenient)
+	var user modeUser
+	err := Scan(&fakeRows{columns: []string{"id"}, dataset: [][]interface{}{{int64(1)}}}, &user)
+	should.Equal(MismatchErr{StructName: "modeUser", MissingFields: []string{"name", "age"}}, err)
+
+	it := NewIterator(&fakeRows{columns: []string{"id", "email"}, dataset: [][]interface{}{{int64(1), "a"}}}, &user)
+	should.False(it.Next())
+	should.IsType(MismatchErr{}, it.Err())
+}
+
+func TestWithModeVariants(t *testing.T) {
+	should := require.New(t)
+	newRows := func() *fakeRows {
+		return &fakeRows{columns: []string{"id", "email"}, dataset: [][]interface{}{{int64(1), "a"}}}
+	}
+	mismatch := MismatchErr{StructName: "modeUser", UnknownColumns: []string{"email"}, MissingFields: []string{"name", "age"}}
+
+	var user modeUser
+	should.Equal(mismatch, ScanCloseWithMode(newRows(), &user, Strict))
+	should.Equal(CloseErr{errCloseForTest}, ScanCloseWithMode(newRows(), &user, Lenient))
+	should.Equal(int64(1), user.ID)
+
+	it := NewIteratorWithMode(newRows(), &user, Strict)
+	should.False(it.Next())
+	should.Equal(mismatch, it.Err())
+	it = NewIteratorWithMode(newRows(), &user, MissingFields)
+	should.False(it.Next())
+	should.Equal(mismatch, it.Err())
+	it = NewIteratorWithMode(newRows(), &user, UnknownColumns)
+	should.False(it.Next())
+	should.Equal(mismatch, it.Err())
+
+	fn := func(u *modeUser) error { return nil }
+	should.Equal(mismatch, ScanEachWithMode(newRows(), fn, Strict))
+	should.NoError(ScanEachWithMode(newRows(), fn, Lenient))
+
+	var users map[int64]modeUser
+	should.Equal(mismatch, ScanIntoMapWithMode(newRows(), "id", &users, Strict))
+	should.NoError(ScanIntoMapWithMode(newRows(), "id", &users, Lenient))
+	should.Equal(int64(1), users[1].ID)
+
+	// the global mode is left untouched
+	it = NewIterator(newRows(), &user)
+	should.True(it.Next())
+	should.NoError(it.Err())
+}
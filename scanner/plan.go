@@ -0,0 +1,381 @@
+package scanner
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
+// fieldPlan is how a column binds to a field of a struct
+type fieldPlan struct {
+	index []int
+	// viaPtr means the field is inside a struct pointer which may be nil
+	viaPtr bool
+	typ    reflect.Type
+	// alloc means the field is a pointer which is allocated before converting
+	alloc bool
+	// keepBytes means the field may keep the []byte it's converted from
+	keepBytes bool
+	wrapErr   convertErrWrapper
+}
+
+// structPlan maps columns to the fields of a struct type,
+// it's computed once for each type and cached
+type structPlan struct {
+	columns map[string][]*fieldPlan
+	// order is the columns in the order of the fields
+	order []string
+	err   error
+}
+
+type planKey struct {
+	typ reflect.Type
+	tag string
+}
+
+var (
+	planCache    sync.Map
+	_scannerType = reflect.TypeOf(new(sql.Scanner)).Elem()
+)
+
+func planOf(typ reflect.Type) (*structPlan, error) {
+	key := planKey{typ: typ, tag: tagName()}
+	plan, ok := planCache.Load(key)
+	if !ok {
+		plan, _ = planCache.LoadOrStore(key, newStructPlan(typ))
+	}
+	return plan.(*structPlan), plan.(*structPlan).err
+}
+
+func newStructPlan(typ reflect.Type) *structPlan {
+	plan := &structPlan{columns: make(map[string][]*fieldPlan)}
+	fields, err := flattenFields(typ, tagName())
+	if nil != err {
+		plan.err = err
+		return plan
+	}
+	for _, field := range fields {
+		if _, ok := plan.columns[field.column]; !ok {
+			plan.order = append(plan.order, field.column)
+		}
+		plan.columns[field.column] = append(plan.columns[field.column], newFieldPlan(typ.Name(), field))
+	}
+	return plan
+}
+
+func newFieldPlan(structName string, field flatField) *fieldPlan {
+	f := &fieldPlan{index: field.index, viaPtr: field.viaPtr, typ: field.field.Type}
+	target := f.typ
+	// if one field is a pointer type, we must allocate memory for it first
+	// except for that the pointer type implements the interface ByteUnmarshaler
+	if target.Kind() == reflect.Ptr && !target.Implements(_byteUnmarshalerType) {
+		f.alloc = true
+		target = target.Elem()
+	}
+	f.keepBytes = keepsBytes(target)
+	fieldName := field.path
+	f.wrapErr = func(from, to reflect.Type) ScanErr {
+		return newScanErr(structName, fieldName, from, to)
+	}
+	return f
+}
+
+// keepsBytes reports whether convert may keep the []byte in a value of typ
+// instead of copying or parsing it
+func keepsBytes(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(_scannerType) {
+		return true
+	}
+	switch k := typ.Kind(); {
+	case k == reflect.String, k == reflect.Bool, isIntSeriesType(k), isUintSeriesType(k), isFloatSeriesType(k):
+		return false
+	}
+	return !isTimeType(typ)
+}
+
+// valuePlan binds a column to a value of typ itself instead of a field
+func valuePlan(typ reflect.Type, column string) *fieldPlan {
+	return newFieldPlan(typ.String(), flatField{
+		field: reflect.StructField{Name: column, Type: typ},
+		path:  column,
+	})
+}
+
+func (f *fieldPlan) bind(structValue reflect.Value, src interface{}, cloneBytes bool) error {
+	if nil == src {
+		return nil
+	}
+	if b, ok := src.([]byte); ok && cloneBytes && f.keepBytes {
+		clone := make([]byte, len(b))
+		copy(clone, b)
+		src = clone
+	}
+	var valuei reflect.Value
+	switch {
+	case nil == f.index:
+		valuei = structValue
+	case f.viaPtr:
+		valuei = settableField(structValue, f.index)
+	default:
+		valuei = structValue.FieldByIndex(f.index)
+	}
+	if f.alloc {
+		valuei.Set(reflect.New(f.typ.Elem()))
+		valuei = valuei.Elem()
+	}
+	return convert(src, valuei, f.wrapErr)
+}
+
+// isScalarType reports whether rows bind to a value of typ as a whole
+// instead of to its fields
+func isScalarType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() != reflect.Struct || isTimeType(typ) || reflect.PtrTo(typ).Implements(_scannerType)
+}
+
+// keyColumn is the column whose value is the key of the map ScanIntoMap scans into
+type keyColumn struct {
+	name  string
+	plan  *fieldPlan
+	value reflect.Value
+	index int
+}
+
+// rowBinder binds each row of rows to a struct by the plan of the struct type,
+// or to a scalar value if the rows have a single column.
+// For *sql.Rows each column is scanned directly into the field it binds to,
+// other Rows are scanned into *interface{} first.
+type rowBinder struct {
+	rows    Rows
+	columns [][]*fieldPlan
+	dest    []interface{}
+	direct  bool
+	scalar  bool
+	key     *keyColumn
+	current reflect.Value
+	err     error
+}
+
+// newRowBinder returns a rowBinder binding rows to the values of typ, key is optional
+func newRowBinder(rows Rows, typ reflect.Type, mode Mode, key *keyColumn) (*rowBinder, error) {
+	if nil == rows {
+		return nil, ErrNilRows
+	}
+	columns, err := rows.Columns()
+	if nil != err {
+		return nil, err
+	}
+	b := &rowBinder{
+		rows:    rows,
+		columns: make([][]*fieldPlan, len(columns)),
+		dest:    make([]interface{}, len(columns)),
+		scalar:  isScalarType(typ),
+		key:     key,
+	}
+	_, b.direct = rows.(*sql.Rows)
+	// the last one wins if columns have the same name
+	last := make(map[string]int, len(columns))
+	for i, name := range columns {
+		last[name] = i
+	}
+	if nil != key {
+		var ok bool
+		if key.index, ok = last[key.name]; !ok {
+			return nil, fmt.Errorf("[scanner]: key column %s is not in the result", key.name)
+		}
+	}
+	if b.scalar {
+		// a scalar binds to the only column besides the key
+		value := -1
+		for i := range columns {
+			if nil == key || key.index != i {
+				if -1 != value {
+					return nil, ErrSingleColumn
+				}
+				value = i
+			}
+		}
+		if -1 == value {
+			return nil, ErrSingleColumn
+		}
+		b.columns[value] = []*fieldPlan{valuePlan(typ, columns[value])}
+	} else {
+		typ, _ = structType(typ)
+		plan, err := planOf(typ)
+		if nil != err {
+			return nil, err
+		}
+		// the key column isn't unknown even if it binds to no field
+		checked := columns
+		if _, ok := plan.columns[key.column()]; nil != key && !ok {
+			checked = append(columns[:key.index:key.index], columns[key.index+1:]...)
+		}
+		if err = checkColumns(typ, plan, checked, mode); nil != err {
+			return nil, err
+		}
+		for i, name := range columns {
+			if last[name] == i {
+				b.columns[i] = plan.columns[name]
+			}
+		}
+	}
+	for i := range columns {
+		isKey := nil != key && key.index == i
+		switch {
+		case !b.direct:
+			b.dest[i] = new(interface{})
+		case nil == b.columns[i] && !isKey:
+			b.dest[i] = discard{}
+		default:
+			b.dest[i] = &fieldScanner{binder: b, fields: b.columns[i], isKey: isKey}
+		}
+	}
+	return b, nil
+}
+
+// column returns the name of the key column, k may be nil
+func (k *keyColumn) column() string {
+	if nil == k {
+		return ""
+	}
+	return k.name
+}
+
+// bind scans the current row into value, which must be settable.
+// The pointers in the way to the struct are allocated.
+func (b *rowBinder) bind(value reflect.Value) (resp error) {
+	defer func() {
+		if r := recover(); nil != r {
+			resp = fmt.Errorf("error:[%v], stack:[%s]", r, string(debug.Stack()))
+		}
+	}()
+	if !b.scalar {
+		value = allocStruct(value)
+	}
+	if nil != b.key {
+		b.key.value.Set(reflect.Zero(b.key.value.Type()))
+	}
+	b.current = value
+	b.err = nil
+	if err := b.rows.Scan(b.dest...); nil != err {
+		if nil != b.err {
+			return b.err
+		}
+		return err
+	}
+	if b.direct {
+		return nil
+	}
+	for i, fields := range b.columns {
+		src := *(b.dest[i].(*interface{}))
+		for _, f := range fields {
+			if err := f.bind(value, src, false); nil != err {
+				return err
+			}
+		}
+		if nil != b.key && b.key.index == i {
+			if err := b.key.plan.bind(b.key.value, src, false); nil != err {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldScanner is the destination of a column which binds to fields of the current struct
+type fieldScanner struct {
+	binder *rowBinder
+	fields []*fieldPlan
+	isKey  bool
+}
+
+func (s *fieldScanner) Scan(src interface{}) error {
+	for _, f := range s.fields {
+		if err := f.bind(s.binder.current, src, true); nil != err {
+			s.binder.err = err
+			return err
+		}
+	}
+	if s.isKey {
+		if err := s.binder.key.plan.bind(s.binder.key.value, src, true); nil != err {
+			s.binder.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// discard is the destination of a column which binds to nothing
+type discard struct{}
+
+func (discard) Scan(interface{}) error { return nil }
+
+// structType returns the struct type typ (indirectly) points to
+func structType(typ reflect.Type) (reflect.Type, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ, typ.Kind() == reflect.Struct
+}
+
+// allocStruct allocates the pointers in the way from v to the struct it points to,
+// and returns the struct
+func allocStruct(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	return v
+}
+
+func scanSlice(rows Rows, target reflect.Value, mode Mode) error {
+	elemType := target.Type().Elem()
+	binder, err := newRowBinder(rows, elemType, mode, nil)
+	if nil != err {
+		return err
+	}
+	var result reflect.Value
+	for rows.Next() {
+		elem := reflect.New(elemType).Elem()
+		if err = binder.bind(elem); nil != err {
+			return err
+		}
+		if !result.IsValid() {
+			result = reflect.MakeSlice(target.Type(), 0, 1)
+		}
+		result = reflect.Append(result, elem)
+	}
+	if err = rowsErr(rows); nil != err {
+		return err
+	}
+	if result.IsValid() {
+		target.Set(result)
+	}
+	return nil
+}
+
+func scanOne(rows Rows, target reflect.Value, mode Mode) error {
+	binder, err := newRowBinder(rows, target.Type(), mode, nil)
+	if nil != err {
+		return err
+	}
+	if !rows.Next() {
+		if err = rowsErr(rows); nil != err {
+			return err
+		}
+		return ErrEmptyResult
+	}
+	if target.Kind() != reflect.Ptr {
+		return binder.bind(target)
+	}
+	value := reflect.New(target.Type()).Elem()
+	if err = binder.bind(value); nil != err {
+		return err
+	}
+	target.Set(value)
+	return nil
+}
@@ -0,0 +1,212 @@
+package scanner
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type planUser struct {
+	ID        int64          `ddb:"id"`
+	Name      string         `ddb:"name"`
+	Age       *int           `ddb:"age"`
+	Active    bool           `ddb:"active"`
+	Raw       []byte         `ddb:"raw"`
+	Nickname  sql.NullString `ddb:"nickname"`
+	CreatedAt string         `ddb:"created_at"`
+	Alias     string         `ddb:"name"`
+	ignored   string         `ddb:"ignored"`
+}
+
+func TestPlanOf(t *testing.T) {
+	should := require.New(t)
+	typ := reflect.TypeOf(planUser{})
+	plan, err := planOf(typ)
+	should.NoError(err)
+	cached, _ := planOf(typ)
+	should.True(plan == cached, "plan should be cached")
+	should.Len(plan.columns, 7)
+	should.Len(plan.columns["name"], 2)
+	should.True(plan.columns["age"][0].alloc)
+	should.True(plan.columns["raw"][0].keepBytes)
+	should.True(plan.columns["nickname"][0].keepBytes)
+	should.False(plan.columns["name"][0].keepBytes)
+	_, ok := plan.columns["ignored"]
+	should.False(ok)
+}
+
+func TestScanDirect(t *testing.T) {
+	should := require.New(t)
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age", "active", "raw", "nickname", "created_at", "unknown"}).
+		AddRow([]byte("1"), []byte("deen"), int64(23), int64(1), []byte("x"), []byte("dd"), created, 1).
+		AddRow(int64(2), "caibirdme", nil, []byte("0"), nil, nil, []byte("2020-01-02 03:04:05"), 2))
+	rows, err := db.Query("select")
+	should.NoError(err)
+	var users []*planUser
+	should.NoError(Scan(rows, &users))
+	age := 23
+	should.Equal([]*planUser{
+		{ID: 1, Name: "deen", Age: &age, Active: true, Raw: []byte("x"), Nickname: sql.NullString{String: "dd", Valid: true}, CreatedAt: "2020-01-02 03:04:05", Alias: "deen"},
+		{ID: 2, Name: "caibirdme", CreatedAt: "2020-01-02 03:04:05", Alias: "caibirdme"},
+	}, users)
+
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3.5, "deen"))
+	rows, err = db.Query("select")
+	should.NoError(err)
+	var user planUser
+	err = Scan(rows, &user)
+	should.Equal(newScanErr("planUser", "ID", reflect.TypeOf(3.5), reflect.TypeOf(int64(0))), err)
+
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	rows, err = db.Query("select")
+	should.NoError(err)
+	should.Equal(ErrEmptyResult, Scan(rows, &user))
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func TestScanPlanSameColumns(t *testing.T) {
+	should := require.New(t)
+	rows := &fakeRows{
+		columns: []string{"id", "name", "name"},
+		dataset: [][]interface{}{{int64(1), "deen", "caibirdme"}},
+	}
+	var user *planUser
+	should.NoError(Scan(rows, &user))
+	should.Equal(&planUser{ID: 1, Name: "caibirdme", Alias: "caibirdme"}, user)
+}
+
+func TestScanScalar(t *testing.T) {
+	should := require.New(t)
+	var ids []int64
+	should.NoError(Scan(&fakeRows{columns: []string{"id"}, dataset: [][]interface{}{{int64(1)}, {[]byte("2")}}}, &ids))
+	should.Equal([]int64{1, 2}, ids)
+
+	var name string
+	should.NoError(Scan(&fakeRows{columns: []string{"name"}, dataset: [][]interface{}{{[]byte("deen")}}}, &name))
+	should.Equal("deen", name)
+
+	var raw []byte
+	should.NoError(Scan(&fakeRows{columns: []string{"raw"}, dataset: [][]interface{}{{[]byte("x")}}}, &raw))
+	should.Equal([]byte("x"), raw)
+
+	err := Scan(&fakeRows{columns: []string{"id", "name"}, dataset: [][]interface{}{{int64(1), "deen"}}}, &ids)
+	should.Equal(ErrSingleColumn, err)
+	should.Equal(ErrEmptyResult, Scan(&fakeRows{columns: []string{"id"}}, &name))
+
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"age"}).AddRow(int64(23)).AddRow(nil))
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(created))
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"nickname"}).AddRow([]byte("dd")).AddRow(nil))
+	rows, err := db.Query("select")
+	should.NoError(err)
+	var ages []*int
+	should.NoError(Scan(rows, &ages))
+	age := 23
+	should.Equal([]*int{&age, nil}, ages)
+
+	rows, err = db.Query("select")
+	should.NoError(err)
+	var createdAt time.Time
+	should.NoError(Scan(rows, &createdAt))
+	should.Equal(created, createdAt)
+
+	rows, err = db.Query("select")
+	should.NoError(err)
+	var nicknames []sql.NullString
+	should.NoError(Scan(rows, &nicknames))
+	should.Equal([]sql.NullString{{String: "dd", Valid: true}, {}}, nicknames)
+	should.NoError(mock.ExpectationsWereMet())
+}
+
+func benchRows(n int) *fakeRows {
+	rows := &fakeRows{columns: []string{"id", "name", "age", "active", "created_at", "unknown"}}
+	for i := 0; i < n; i++ {
+		rows.dataset = append(rows.dataset, []interface{}{int64(i), []byte(fmt.Sprintf("user%d", i)), int64(20), int64(1), []byte("2020-01-02 03:04:05"), int64(0)})
+	}
+	return rows
+}
+
+func BenchmarkScanLegacy(b *testing.B) {
+	rows := benchRows(100)
+	for i := 0; i < b.N; i++ {
+		rows.idx = 0
+		var users []planUser
+		data, err := resolveDataFromRows(rows)
+		if nil == err {
+			err = legacyBindSlice(data, &users)
+		}
+		if nil != err {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	rows := benchRows(100)
+	for i := 0; i < b.N; i++ {
+		rows.idx = 0
+		var users []planUser
+		if err := Scan(rows, &users); nil != err {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchSQLRows(b *testing.B, db *sql.DB, mock sqlmock.Sqlmock) *sql.Rows {
+	mockRows := sqlmock.NewRows([]string{"id", "name", "age", "active", "created_at", "unknown"})
+	for i := 0; i < 100; i++ {
+		mockRows.AddRow(int64(i), []byte(fmt.Sprintf("user%d", i)), int64(20), int64(1), []byte("2020-01-02 03:04:05"), nil)
+	}
+	mock.ExpectQuery("select").WillReturnRows(mockRows)
+	rows, err := db.Query("select")
+	if nil != err {
+		b.Fatal(err)
+	}
+	return rows
+}
+
+func BenchmarkScanSQLRowsLegacy(b *testing.B) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := benchSQLRows(b, db, mock)
+		b.StartTimer()
+		var users []planUser
+		data, err := resolveDataFromRows(rows)
+		if nil == err {
+			err = legacyBindSlice(data, &users)
+		}
+		if nil != err {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
+
+func BenchmarkScanSQLRows(b *testing.B) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := benchSQLRows(b, db, mock)
+		b.StartTimer()
+		var users []planUser
+		if err := Scan(rows, &users); nil != err {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"fmt"
-	"runtime/debug"
 )
 
 // ByteUnmarshaler is the interface implemented by types
@@ -50,6 +49,8 @@ var (
 	ErrSliceToString = errors.New("[scanner]: can't transmute a non-uint8 slice to string")
 	//ErrEmptyResult occurs when target of Scan isn't slice and the result of the query is empty
 	ErrEmptyResult = errors.New(`[scanner]: empty result`)
+	//ErrSingleColumn means the result must have a single column to be scanned into a non-struct target
+	ErrSingleColumn = errors.New("[scanner]: a single column is required to scan into a non-struct target")
 )
 
 //SetTagName can be set only once
@@ -78,30 +79,22 @@ func newScanErr(structName, fieldName string, from, to reflect.Type) ScanErr {
 // Don't forget to close the rows
 // When the target is not a pointer of slice, ErrEmptyResult
 // may be returned if the query result is empty
+// When the target is not a struct (or a slice of it), such as *int64 or *[]string,
+// the result must have a single column
 func Scan(rows Rows, target interface{}) error {
+	return scan(rows, target, defaultMode)
+}
+
+func scan(rows Rows, target interface{}, mode Mode) error {
 	if nil == target || reflect.ValueOf(target).IsNil() || reflect.TypeOf(target).Kind() != reflect.Ptr {
 		return ErrTargetNotSettable
 	}
 
-	data, err := resolveDataFromRows(rows)
-	if nil != err {
-		return err
-	}
-
-	switch reflect.TypeOf(target).Elem().Kind() {
-	case reflect.Slice:
-		if nil == data {
-			return nil
-		}
-		err = bindSlice(data, target)
-	default:
-		if nil == data {
-			return ErrEmptyResult
-		}
-		err = bind(data[0], target)
+	targetObj := reflect.ValueOf(target).Elem()
+	if targetObj.Kind() == reflect.Slice && targetObj.Type().Elem().Kind() != reflect.Uint8 {
+		return scanSlice(rows, targetObj, mode)
 	}
-
-	return err
+	return scanOne(rows, targetObj, mode)
 }
 
 // ScanMap returns the result in the form of []map[string]interface{}
@@ -192,7 +185,12 @@ func ScanMapClose(rows Rows) ([]map[string]interface{}, error) {
 // Not necessary exec the rows.Close after calling this.
 // Close is idempotent and does not affect the result of Err.
 func ScanClose(rows Rows, target interface{}) error {
-	err := Scan(rows, target)
+	return ScanCloseWithMode(rows, target, defaultMode)
+}
+
+// ScanCloseWithMode is the same as ScanWithMode and closes the rows
+func ScanCloseWithMode(rows Rows, target interface{}, mode Mode) error {
+	err := scan(rows, target, mode)
 	if nil != rows {
 		errClose := rows.Close()
 		if err == nil {
@@ -202,90 +200,6 @@ func ScanClose(rows Rows, target interface{}) error {
 	return err
 }
 
-//caller must guarantee to pass a &slice as the second param
-func bindSlice(arr []map[string]interface{}, target interface{}) error {
-	targetObj := reflect.ValueOf(target)
-	if !targetObj.Elem().CanSet() {
-		return ErrTargetNotSettable
-	}
-	length := len(arr)
-	valueArrObj := reflect.MakeSlice(targetObj.Elem().Type(), 0, length)
-	typeObj := valueArrObj.Type().Elem()
-	var err error
-	for i := 0; i < length; i++ {
-		newObj := reflect.New(typeObj)
-		newObjInterface := newObj.Interface()
-		err = bind(arr[i], newObjInterface)
-		if nil != err {
-			return err
-		}
-		valueArrObj = reflect.Append(valueArrObj, newObj.Elem())
-	}
-	targetObj.Elem().Set(valueArrObj)
-	return nil
-}
-
-func bind(result map[string]interface{}, target interface{}) (resp error) {
-	if nil != resp {
-		return
-	}
-	defer func() {
-		if r := recover(); nil != r {
-			resp = fmt.Errorf("error:[%v], stack:[%s]", r, string(debug.Stack()))
-		}
-	}()
-	valueObj := reflect.ValueOf(target).Elem()
-	if !valueObj.CanSet() {
-		return ErrTargetNotSettable
-	}
-	typeObj := valueObj.Type()
-	if typeObj.Kind() == reflect.Ptr {
-		ptrType := typeObj.Elem()
-		newObj := reflect.New(ptrType)
-		newObjInterface := newObj.Interface()
-		err := bind(result, newObjInterface)
-		if nil == err {
-			valueObj.Set(newObj)
-		}
-		return err
-	}
-	typeObjName := typeObj.Name()
-
-	for i := 0; i < valueObj.NumField(); i++ {
-		fieldTypeI := typeObj.Field(i)
-		fieldName := fieldTypeI.Name
-
-		//for convenience
-		wrapErr := func(from, to reflect.Type) ScanErr {
-			return newScanErr(typeObjName, fieldName, from, to)
-		}
-
-		valuei := valueObj.Field(i)
-		if !valuei.CanSet() {
-			continue
-		}
-		tagName, ok := lookUpTagName(fieldTypeI)
-		if !ok || "" == tagName {
-			continue
-		}
-		mapValue, ok := result[tagName]
-		if !ok || mapValue == nil {
-			continue
-		}
-		// if one field is a pointer type, we must allocate memory for it first
-		// except for that the pointer type implements the interface ByteUnmarshaler
-		if fieldTypeI.Type.Kind() == reflect.Ptr && !fieldTypeI.Type.Implements(_byteUnmarshalerType) {
-			valuei.Set(reflect.New(fieldTypeI.Type.Elem()))
-			valuei = valuei.Elem()
-		}
-		err := convert(mapValue, valuei, wrapErr)
-		if nil != err {
-			return err
-		}
-	}
-	return nil
-}
-
 var _byteUnmarshalerType = reflect.TypeOf(new(ByteUnmarshaler)).Elem()
 
 type convertErrWrapper func(from, to reflect.Type) ScanErr
@@ -332,19 +246,11 @@ func resolveDataFromRows(rows Rows) ([]map[string]interface{}, error) {
 	return result, nil
 }
 
-func lookUpTagName(typeObj reflect.StructField) (string, bool) {
-	var tName string
+func tagName() string {
 	if "" != userDefinedTagName {
-		tName = userDefinedTagName
-	} else {
-		tName = DefaultTagName
-	}
-	name, ok := typeObj.Tag.Lookup(tName)
-	if !ok {
-		return "", false
+		return userDefinedTagName
 	}
-	name = resolveTagName(name)
-	return name, ok
+	return DefaultTagName
 }
 
 func convert(mapValue interface{}, valuei reflect.Value, wrapErr convertErrWrapper) error {
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"math"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -26,7 +27,7 @@ func TestBindOne(t *testing.T) {
 		"name": name,
 		"ag":   age,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(name, p.Name)
@@ -42,12 +43,12 @@ func TestScanner_Time(t *testing.T) {
 	var mp = map[string]interface{}{
 		"qwe": now,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(now, p.Data.d)
 	mp["qwe"] = 10
-	err = bind(mp, &p)
+	err = scanFromMap(mp, &p)
 	should.EqualError(err, "not time.Time type")
 }
 
@@ -75,7 +76,7 @@ func TestBindOne_byte_string(t *testing.T) {
 		"name": name,
 		"ag":   age,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(string(name), p.Name)
@@ -94,7 +95,7 @@ func TestBindOne_byte_uint8(t *testing.T) {
 		"name": name,
 		"ag":   age,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(name, p.Name)
@@ -113,7 +114,7 @@ func TestBindOne_byte_uint8_pointer(t *testing.T) {
 		"name": name,
 		"ag":   age,
 	}
-	err := bind(mp, p)
+	err := scanFromMap(mp, p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(name, p.Name)
@@ -132,7 +133,7 @@ func TestBindOne_uint8_byte(t *testing.T) {
 		"name": name,
 		"ag":   age,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(name, p.Name)
@@ -148,7 +149,7 @@ func TestBindOne_float(t *testing.T) {
 	var mp = map[string]interface{}{
 		"sl": salary,
 	}
-	err := bind(mp, &p)
+	err := scanFromMap(mp, &p)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(salary, p.Salary)
@@ -164,7 +165,7 @@ func TestBindSlice(t *testing.T) {
 	for _, v := range testCases {
 		data = append(data, map[string]interface{}{"age": v})
 	}
-	err := bindSlice(data, &students)
+	err := scanFromMaps(data, &students)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(len(testCases), len(students))
@@ -193,7 +194,7 @@ func Test_Scan_PointerArr(t *testing.T) {
 			"sala": float32(0.0),
 		},
 	)
-	err := bindSlice(data, &stus)
+	err := scanFromMaps(data, &stus)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(len(data), len(stus))
@@ -208,7 +209,7 @@ func Test_Bind_Float32_2_Float64(t *testing.T) {
 		Num float64 `ddb:"num"`
 	}
 	var a A
-	err := bind(map[string]interface{}{
+	err := scanFromMap(map[string]interface{}{
 		"num": float32(10.5),
 	}, &a)
 	should := require.New(t)
@@ -221,7 +222,7 @@ func Test_Bind_Float64_2_Float32(t *testing.T) {
 		Num float32 `ddb:"num"`
 	}
 	var a A
-	err := bind(map[string]interface{}{
+	err := scanFromMap(map[string]interface{}{
 		"num": float64(10.5),
 	}, &a)
 	should := require.New(t)
@@ -235,7 +236,7 @@ func Test_Bind_int64_2_uint64(t *testing.T) {
 		Age uint8  `ddb:"age"`
 	}
 	var a A
-	err := bind(map[string]interface{}{
+	err := scanFromMap(map[string]interface{}{
 		"num": int64(10),
 		"age": int64(20),
 	}, &a)
@@ -255,7 +256,7 @@ func Test_Ignore_Unexported_Field(t *testing.T) {
 		"name": []byte("Tommmm"),
 		"age":  int64(100),
 	}
-	err := bind(data, &Tom)
+	err := scanFromMap(data, &Tom)
 	should := require.New(t)
 	should.NoError(err)
 	should.Equal(0, Tom.age)
@@ -272,14 +273,14 @@ func Test_Bind_Time_2_String(t *testing.T) {
 	}
 	var tObj Whatever
 	should := require.New(t)
-	err := bind(data, &tObj)
+	err := scanFromMap(data, &tObj)
 	should.NoError(err, "time.Time should transform to string and bind to string type")
 	should.Equal(now.Format("2006-01-02 15:04:05"), tObj.When)
 	type WillErr struct {
 		When int `ddb:"create_time"`
 	}
 	var some WillErr
-	err = bind(data, &some)
+	err = scanFromMap(data, &some)
 	should.Error(err, "time.Time could only bind to time.Time&string type %v", some)
 }
 
@@ -293,7 +294,7 @@ func Test_Bind_Slice_2_Time(t *testing.T) {
 	}
 	var tObj Whatever
 	should := require.New(t)
-	err := bind(data, &tObj)
+	err := scanFromMap(data, &tObj)
 	should.NoError(err, "[]uint8 should try to cast to time.Time")
 	should.Equal(now.Unix(), tObj.When.Unix())
 }
@@ -399,7 +400,7 @@ func Test_Slice_2_Int(t *testing.T) {
 		mp := map[string]interface{}{
 			"age": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -449,7 +450,7 @@ func Test_Scan_Pointer(t *testing.T) {
 		mp := map[string]interface{}{
 			"age": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if err == nil {
 			should.NoError(err)
 		} else {
@@ -521,7 +522,7 @@ func Test_Scan_Multi_Pointer(t *testing.T) {
 	should := require.New(t)
 	for idx, tc := range testData {
 		var u user
-		err := bind(tc.in, &u)
+		err := scanFromMap(tc.in, &u)
 		if err == nil {
 			should.NoError(err)
 		} else {
@@ -587,7 +588,7 @@ func Test_Slice_2_UInt(t *testing.T) {
 		mp := map[string]interface{}{
 			"age": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -643,7 +644,7 @@ func Test_Slice_2_Float(t *testing.T) {
 		mp := map[string]interface{}{
 			"score": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -707,7 +708,7 @@ func Test_int64_2_bool(t *testing.T) {
 	should := require.New(t)
 	for _, tc := range testData {
 		var u user
-		err := bind(tc.in, &u)
+		err := scanFromMap(tc.in, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -791,7 +792,7 @@ func Test_int64_2_string(t *testing.T) {
 	should := require.New(t)
 	for _, tc := range testData {
 		var u user
-		err := bind(tc.in, &u)
+		err := scanFromMap(tc.in, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -842,7 +843,7 @@ func Test_uint8_2_any(t *testing.T) {
 	should := require.New(t)
 	for _, tc := range testData {
 		var u user
-		err := bind(tc.in, &u)
+		err := scanFromMap(tc.in, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -890,7 +891,7 @@ func Test_sql_scanner(t *testing.T) {
 		mp := map[string]interface{}{
 			"name": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -938,7 +939,7 @@ func Test_sql_scanner_with_pointer(t *testing.T) {
 		mp := map[string]interface{}{
 			"name": tc.in,
 		}
-		err := bind(mp, &u)
+		err := scanFromMap(mp, &u)
 		if tc.err == nil {
 			should.NoError(err)
 		} else {
@@ -959,6 +960,29 @@ func TestTagSetOnlyOnce(t *testing.T) {
 	userDefinedTagName = DefaultTagName
 }
 
+// scanFromMap scans the row of the columns in mp into target by Scan
+func scanFromMap(mp map[string]interface{}, target interface{}) error {
+	return scanFromMaps([]map[string]interface{}{mp}, target)
+}
+
+// scanFromMaps scans the rows of the columns in data into target by Scan,
+// the rows must have the same columns, which are sorted to scan in a stable order
+func scanFromMaps(data []map[string]interface{}, target interface{}) error {
+	rows := &fakeRows{}
+	for column := range data[0] {
+		rows.columns = append(rows.columns, column)
+	}
+	sort.Strings(rows.columns)
+	for _, mp := range data {
+		row := make([]interface{}, len(rows.columns))
+		for i, column := range rows.columns {
+			row[i] = mp[column]
+		}
+		rows.dataset = append(rows.dataset, row)
+	}
+	return Scan(rows, target)
+}
+
 type fakeRows struct {
 	columns []string
 	dataset [][]interface{}
@@ -993,7 +1017,12 @@ func (r *fakeRows) Scan(dt ...interface{}) (err error) {
 	}()
 	for i := 0; i < lendt; i++ {
 		data := r.dataset[r.idx][i]
-		reflect.ValueOf(dt[i]).Elem().Set(reflect.ValueOf(data))
+		dest := reflect.ValueOf(dt[i]).Elem()
+		if nil == data {
+			dest.Set(reflect.Zero(dest.Type()))
+			continue
+		}
+		dest.Set(reflect.ValueOf(data))
 	}
 	return nil
 }
@@ -1146,7 +1175,7 @@ func TestUnmarshalByte(t *testing.T) {
 		if idx >= 2 {
 			student.Extra = &extraInfo{}
 		}
-		err := bind(tc.mapv, &student)
+		err := scanFromMap(tc.mapv, &student)
 		should.Equal(tc.err, err, "idx:%d", idx)
 		should.Equal(tc.expect, student, "idx:%d", idx)
 	}
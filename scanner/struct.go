@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AmbiguousColumnErr is returned when a column maps to the fields of
+// different embedded or nested structs at the same depth
+type AmbiguousColumnErr struct {
+	structName, column string
+	fields             []string
+}
+
+func (e AmbiguousColumnErr) Error() string {
+	return fmt.Sprintf("[scanner]: column %s of %s is ambiguous between %s", e.column, e.structName, strings.Join(e.fields, ", "))
+}
+
+// flatField is a field of a struct, fields of embedded structs and
+// nested structs tagged with prefix are flattened
+type flatField struct {
+	field   reflect.StructField
+	index   []int
+	column  string
+	options string
+	// path is the path of the field such as BaseModel.ID
+	path  string
+	depth int
+	// viaPtr means the field is inside an embedded or nested struct pointer
+	viaPtr bool
+}
+
+// flattenFields returns the fields of typ named by useTag, or by their names if useTag is empty.
+// Anonymous struct fields without the tag are flattened, so are struct fields tagged with the
+// prefix option whose columns are prefixed: `ddb:"user,prefix=user_"`.
+// A column maps to the shallowest fields, it's ambiguous if they belong to different structs.
+func flattenFields(typ reflect.Type, useTag string) ([]flatField, error) {
+	var all []flatField
+	walkFields(typ, useTag, "", nil, "", 0, false, map[reflect.Type]bool{typ: true}, &all)
+	shallowest := make(map[string]int)
+	for _, f := range all {
+		if depth, ok := shallowest[f.column]; !ok || f.depth < depth {
+			shallowest[f.column] = f.depth
+		}
+	}
+	var fields []flatField
+	parents := make(map[string]string)
+	for _, f := range all {
+		if f.depth != shallowest[f.column] {
+			continue
+		}
+		parent := strings.TrimSuffix(f.path, f.field.Name)
+		if p, ok := parents[f.column]; ok && p != parent {
+			return nil, newAmbiguousColumnErr(typ.Name(), f.column, all)
+		}
+		parents[f.column] = parent
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func newAmbiguousColumnErr(structName, column string, all []flatField) AmbiguousColumnErr {
+	err := AmbiguousColumnErr{structName: structName, column: column}
+	for _, f := range all {
+		if f.column == column {
+			err.fields = append(err.fields, f.path)
+		}
+	}
+	return err
+}
+
+func walkFields(typ reflect.Type, useTag, prefix string, index []int, path string, depth int, viaPtr bool, visiting map[reflect.Type]bool, fields *[]flatField) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		exported := "" == field.PkgPath
+		name, options, hasTag := field.Name, "", false
+		if "" != useTag {
+			var tag string
+			tag, hasTag = field.Tag.Lookup(useTag)
+			name, options = splitTag(tag)
+		}
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		nested, isPtr := field.Type, false
+		if nested.Kind() == reflect.Ptr {
+			nested, isPtr = nested.Elem(), true
+		}
+		subPrefix, hasPrefix := tagOption(options, "prefix")
+		if nested.Kind() == reflect.Struct && "-" != name && ((field.Anonymous && !hasTag) || hasPrefix) {
+			// exported fields of an embedded unexported struct are still settable,
+			// while an unexported pointer can't be allocated
+			if visiting[nested] || (!exported && (isPtr || !field.Anonymous)) {
+				continue
+			}
+			visiting[nested] = true
+			walkFields(nested, useTag, prefix+subPrefix, fieldIndex, path+field.Name+".", depth+1, viaPtr || isPtr, visiting, fields)
+			delete(visiting, nested)
+			continue
+		}
+		if !exported || ("" != useTag && !hasTag) || "" == name || "-" == name {
+			continue
+		}
+		*fields = append(*fields, flatField{
+			field:   field,
+			index:   fieldIndex,
+			column:  prefix + name,
+			options: options,
+			path:    path + field.Name,
+			depth:   depth,
+			viaPtr:  viaPtr,
+		})
+	}
+}
+
+func splitTag(tag string) (name, options string) {
+	idx := strings.IndexByte(tag, ',')
+	if -1 == idx {
+		return tag, ""
+	}
+	return tag[:idx], tag[idx+1:]
+}
+
+// tagOption returns the value of option key=value in options
+func tagOption(options, key string) (string, bool) {
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		if strings.HasPrefix(option, key+"=") {
+			return option[len(key)+1:], true
+		}
+	}
+	return "", false
+}
+
+// readField returns the field of v at index, false if it's inside a nil pointer
+func readField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// settableField returns the field of v at index, the nil pointers in the way are allocated
+func settableField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type BaseModel struct {
+	ID        int64     `ddb:"id"`
+	CreatedAt time.Time `ddb:"created_at"`
+}
+
+type Audit struct {
+	Operator string `ddb:"operator"`
+}
+
+type embedUser struct {
+	BaseModel
+	*Audit
+	Name string `ddb:"name"`
+}
+
+type Profile struct {
+	ID   int64  `ddb:"id"`
+	City string `ddb:"city"`
+}
+
+type embedOrder struct {
+	*BaseModel
+	ID      int64     `ddb:"order_id"`
+	Price   float64   `ddb:"price"`
+	User    embedUser `ddb:"user,prefix=user_"`
+	Profile *Profile  `ddb:"profile,prefix=profile_"`
+	Ignored Profile   `ddb:"-"`
+}
+
+func TestFlattenFields(t *testing.T) {
+	should := require.New(t)
+	fields, err := flattenFields(typeOf(embedOrder{}), DefaultTagName)
+	should.NoError(err)
+	var columns, paths []string
+	for _, f := range fields {
+		columns = append(columns, f.column)
+		paths = append(paths, f.path)
+	}
+	should.Equal([]string{"id", "created_at", "order_id", "price", "user_id", "user_created_at", "user_operator", "user_name", "profile_id", "profile_city"}, columns)
+	should.Equal([]string{"BaseModel.ID", "BaseModel.CreatedAt", "ID", "Price", "User.BaseModel.ID", "User.BaseModel.CreatedAt", "User.Audit.Operator", "User.Name", "Profile.ID", "Profile.City"}, paths)
+
+	type shadow struct {
+		BaseModel
+		ID int64 `ddb:"id"`
+	}
+	fields, err = flattenFields(typeOf(shadow{}), DefaultTagName)
+	should.NoError(err)
+	should.Len(fields, 2)
+	should.Equal("BaseModel.CreatedAt", fields[0].path)
+	should.Equal("ID", fields[1].path, "the shallower field shadows BaseModel.ID")
+
+	type ambiguous struct {
+		BaseModel
+		Profile
+	}
+	_, err = flattenFields(typeOf(ambiguous{}), DefaultTagName)
+	should.Equal(AmbiguousColumnErr{structName: "ambiguous", column: "id", fields: []string{"BaseModel.ID", "Profile.ID"}}, err)
+	should.Equal("[scanner]: column id of ambiguous is ambiguous between BaseModel.ID, Profile.ID", err.Error())
+}
+
+func TestScanEmbedded(t *testing.T) {
+	should := require.New(t)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	rows := &fakeRows{
+		columns: []string{"id", "created_at", "order_id", "price", "user_id", "user_name", "operator", "profile_city"},
+		dataset: [][]interface{}{
+			{int64(1), created, int64(10), 9.9, int64(2), "deen", "admin", "Beijing"},
+		},
+	}
+	var orders []embedOrder
+	should.NoError(Scan(rows, &orders))
+	should.Equal([]embedOrder{{
+		BaseModel: &BaseModel{ID: 1, CreatedAt: created},
+		ID:        10,
+		Price:     9.9,
+		User:      embedUser{BaseModel: BaseModel{ID: 2}, Name: "deen"},
+		Profile:   &Profile{City: "Beijing"},
+	}}, orders)
+
+	db, mock, err := sqlmock.New()
+	should.NoError(err)
+	defer db.Close()
+	mock.ExpectQuery("select").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "operator"}).
+		AddRow(int64(1), []byte("deen"), []byte("admin")).
+		AddRow(int64(2), []byte("caibirdme"), nil))
+	sqlRows, err := db.Query("select")
+	should.NoError(err)
+	var users []embedUser
+	should.NoError(Scan(sqlRows, &users))
+	should.Equal([]embedUser{
+		{BaseModel: BaseModel{ID: 1}, Audit: &Audit{Operator: "admin"}, Name: "deen"},
+		{BaseModel: BaseModel{ID: 2}, Name: "caibirdme"},
+	}, users)
+
+	type ambiguous struct {
+		BaseModel
+		Profile
+	}
+	var result []ambiguous
+	err = Scan(&fakeRows{columns: []string{"id"}, dataset: [][]interface{}{{int64(1)}}}, &result)
+	should.IsType(AmbiguousColumnErr{}, err)
+}
+
+func TestMapEmbedded(t *testing.T) {
+	should := require.New(t)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	order := embedOrder{
+		BaseModel: &BaseModel{ID: 1, CreatedAt: created},
+		ID:        10,
+		User:      embedUser{BaseModel: BaseModel{ID: 2}, Audit: &Audit{Operator: "admin"}, Name: "deen"},
+	}
+	m, err := Map(order, DefaultTagName)
+	should.NoError(err)
+	should.Equal(map[string]interface{}{
+		"id":              int64(1),
+		"created_at":      created,
+		"order_id":        int64(10),
+		"price":           0.0,
+		"user_id":         int64(2),
+		"user_created_at": time.Time{},
+		"user_operator":   "admin",
+		"user_name":       "deen",
+	}, m)
+
+	m, err = Map(embedUser{Name: "deen"}, "")
+	should.NoError(err)
+	should.Equal(map[string]interface{}{"ID": int64(0), "CreatedAt": time.Time{}, "Name": "deen"}, m, "fields of nil embedded pointers are skipped")
+
+	fields, err := Fields(&embedUser{BaseModel: BaseModel{ID: 3}})
+	should.NoError(err)
+	should.Len(fields, 3)
+	should.Equal(Field{Column: "id", Value: int64(3)}, fields[0])
+}
+
+func typeOf(v interface{}) reflect.Type {
+	return reflect.TypeOf(v)
+}